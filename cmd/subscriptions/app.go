@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/mirrorblade/subscriptions/internal/config"
+	"github.com/mirrorblade/subscriptions/internal/metrics"
+	"github.com/mirrorblade/subscriptions/internal/rbac"
+	"github.com/mirrorblade/subscriptions/internal/repository"
+	"github.com/mirrorblade/subscriptions/internal/repository/postgresql"
+	"github.com/mirrorblade/subscriptions/internal/service"
+	"github.com/mirrorblade/subscriptions/internal/tracing"
+)
+
+func newPool(config *config.Config) (*pgxpool.Pool, error) {
+	dsn := fmt.Sprintf("postgresql://%s:%s@%s:%s/%s?sslmode=disable", config.Database.User, config.Database.Password, config.Database.Host, config.Database.Port, config.Database.Name)
+
+	poolConfig, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	poolConfig.ConnConfig.Tracer = tracing.NewQueryTracer()
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+
+		return nil, err
+	}
+
+	return pool, nil
+}
+
+// bypassesRLS reports whether the role of pool is not subject to row-level
+// security, which leaves the isolation of tenants to the queries alone.
+func bypassesRLS(pool *pgxpool.Pool) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var bypass bool
+
+	err := pool.QueryRow(ctx, "SELECT rolsuper OR rolbypassrls FROM pg_roles WHERE rolname = current_user").Scan(&bypass)
+
+	return bypass, err
+}
+
+func newService(pool *pgxpool.Pool, config *config.Config, collector *metrics.Metrics) (*service.Service, error) {
+	policy, err := rbac.Load(config.RBAC.Policy)
+	if err != nil {
+		return nil, fmt.Errorf("load policy: %w", err)
+	}
+
+	subscriptionsRepository := metrics.NewSubscriptions(postgresql.NewSubscriptions(pool, "subscriptions", "subscriptions_audit"), collector)
+	exchangeRatesRepository := postgresql.NewExchangeRates(pool, "exchange_rates")
+	auditRepository := postgresql.NewAudit(pool, "subscriptions_audit")
+	idempotencyKeysRepository := postgresql.NewIdempotencyKeys(pool, "idempotency_keys")
+	apiKeysRepository := postgresql.NewAPIKeys(pool, "api_keys")
+	repository := repository.New(subscriptionsRepository, exchangeRatesRepository, auditRepository, idempotencyKeysRepository, apiKeysRepository)
+
+	subscriptionsService := tracing.NewSubscriptions(service.NewRBACSubscriptions(service.NewSubscriptionsService(repository.Subscriptions, repository.ExchangeRates, repository.Audit, policy), policy))
+	idempotencyService := service.NewIdempotencyService(repository.IdempotencyKeys, config.Server.Idempotency.TTL)
+	importService := service.NewImportService(subscriptionsService, bluemonday.UGCPolicy(), config.Import.BatchSize)
+	apiKeysService := service.NewAPIKeysService(repository.APIKeys)
+
+	return service.New(subscriptionsService, idempotencyService, importService, apiKeysService), nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/mirrorblade/subscriptions/internal/config"
+	"github.com/mirrorblade/subscriptions/internal/domain"
+	"github.com/mirrorblade/subscriptions/internal/importer"
+	"github.com/mirrorblade/subscriptions/internal/metrics"
+)
+
+// importSubscriptions imports subscriptions from the file given in args, or
+// from stdin if the file is "-", and prints the report.
+func importSubscriptions(config *config.Config, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := flags.String("format", "", "format of the file: csv or ndjson (default: detected from the file extension)")
+	dryRun := flags.Bool("dry-run", false, "validate the file without importing it")
+	tenantID := flags.String("tenant", config.Server.Tenancy.Default, "tenant to import the subscriptions into")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: subscriptions import [-format csv|ndjson] [-dry-run] [-tenant TENANT] FILE")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+
+		return fmt.Errorf("expected one file, got %d", flags.NArg())
+	}
+
+	path := flags.Arg(0)
+
+	if *format == "" {
+		*format = formatFromExtension(path)
+	}
+
+	if !importer.Format(*format).Valid() {
+		return fmt.Errorf("%w: %q", importer.ErrUnsupportedFormat, *format)
+	}
+
+	if !domain.ValidTenantID(*tenantID) {
+		return fmt.Errorf("%w: %q", domain.ErrInvalidTenant, *tenantID)
+	}
+
+	var file io.Reader = os.Stdin
+
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		file = f
+	}
+
+	pool, err := newPool(config)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	service, err := newService(pool, config, metrics.New())
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// The command line is trusted with the subscriptions of every user.
+	ctx = domain.WithPrincipal(ctx, domain.SystemPrincipal)
+	ctx = domain.WithTenant(ctx, *tenantID)
+
+	report, err := service.Import.Import(ctx, file, importer.Format(*format), *dryRun)
+	if err != nil {
+		return err
+	}
+
+	for _, rowError := range report.Errors {
+		fmt.Printf("row %d: %s\n", rowError.Row, rowError.Message)
+	}
+
+	if report.DryRun {
+		fmt.Printf("dry run: %d rows, %d valid, %d invalid\n", report.Total, report.Valid, len(report.Errors))
+	} else {
+		fmt.Printf("%d rows, %d imported, %d invalid\n", report.Total, report.Imported, len(report.Errors))
+	}
+
+	if len(report.Errors) != 0 {
+		return fmt.Errorf("%d rows were not imported", len(report.Errors))
+	}
+
+	return nil
+}
+
+func formatFromExtension(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return string(importer.FormatCSV)
+	case ".ndjson", ".jsonl":
+		return string(importer.FormatNDJSON)
+	}
+
+	return ""
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mirrorblade/subscriptions/internal/config"
+	"github.com/mirrorblade/subscriptions/internal/domain"
+	"github.com/mirrorblade/subscriptions/internal/metrics"
+	"github.com/mirrorblade/subscriptions/internal/service"
+)
+
+const keysUsage = `usage: subscriptions keys command
+
+commands:
+  create  mint a key and print it
+  list    list keys
+  revoke  revoke a key`
+
+// manageKeys manages the API keys of service callers.
+func manageKeys(config *config.Config, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, keysUsage)
+
+		return errors.New("expected a command")
+	}
+
+	command, args := args[0], args[1:]
+
+	var run func(context.Context, *service.Service, []string) error
+
+	switch command {
+	case "create":
+		run = func(ctx context.Context, service *service.Service, args []string) error {
+			return createKey(ctx, service, config.Server.Tenancy.Default, args)
+		}
+	case "list":
+		run = listKeys
+	case "revoke":
+		run = revokeKey
+	default:
+		fmt.Fprintln(os.Stderr, keysUsage)
+
+		return fmt.Errorf("unknown command %q", command)
+	}
+
+	pool, err := newPool(config)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	service, err := newService(pool, config, metrics.New())
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	ctx = domain.WithPrincipal(ctx, domain.SystemPrincipal)
+
+	return run(ctx, service, args)
+}
+
+func createKey(ctx context.Context, service *service.Service, defaultTenant string, args []string) error {
+	flags := flag.NewFlagSet("keys create", flag.ContinueOnError)
+	name := flags.String("name", "", "name of the caller the key is for")
+	tenantID := flags.String("tenant", defaultTenant, "tenant the key works within")
+	scopes := flags.String("scopes", domain.ScopeRead, "comma-separated scopes: read, write, admin")
+	roles := flags.String("roles", "", "comma-separated roles of the policy (default: the default roles)")
+	ttl := flags.Duration("ttl", 0, "time until the key expires (default: never)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: subscriptions keys create -name NAME [-tenant TENANT] [-scopes read,write,admin] [-roles ROLES] [-ttl DURATION]")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var roleList []string
+	if *roles != "" {
+		roleList = strings.Split(*roles, ",")
+	}
+
+	key, secret, err := service.APIKeys.Create(ctx, *name, *tenantID, strings.Split(*scopes, ","), roleList, *ttl)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("id: %s\ntenant: %s\nscopes: %s\n", key.ID, key.TenantID, strings.Join(key.Scopes, ","))
+	if len(key.Roles) != 0 {
+		fmt.Printf("roles: %s\n", strings.Join(key.Roles, ","))
+	}
+	if key.ExpiresAt != nil {
+		fmt.Printf("expires at: %s\n", key.ExpiresAt.Format(time.RFC3339))
+	}
+	fmt.Printf("key: %s\n\nThe key is shown only once, store it now.\n", secret)
+
+	return nil
+}
+
+func listKeys(ctx context.Context, service *service.Service, args []string) error {
+	if len(args) != 0 {
+		return errors.New("usage: subscriptions keys list")
+	}
+
+	keys, err := service.APIKeys.GetList(ctx)
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "ID\tNAME\tPREFIX\tTENANT\tSCOPES\tROLES\tCREATED\tEXPIRES\tREVOKED")
+
+	for _, key := range keys {
+		roles := "-"
+		if len(key.Roles) != 0 {
+			roles = strings.Join(key.Roles, ",")
+		}
+
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			key.ID, key.Name, key.Prefix, key.TenantID, strings.Join(key.Scopes, ","), roles, key.CreatedAt.Format(time.RFC3339), formatTime(key.ExpiresAt), formatTime(key.RevokedAt))
+	}
+
+	return writer.Flush()
+}
+
+func revokeKey(ctx context.Context, service *service.Service, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: subscriptions keys revoke ID")
+	}
+
+	id, err := uuid.Parse(args[0])
+	if err != nil {
+		return domain.ErrInvalidID
+	}
+
+	if err := service.APIKeys.Revoke(ctx, id); err != nil {
+		return err
+	}
+
+	fmt.Printf("key %s was revoked\n", id)
+
+	return nil
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+
+	return t.Format(time.RFC3339)
+}
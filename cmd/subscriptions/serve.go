@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/mirrorblade/subscriptions/internal/auth"
+	"github.com/mirrorblade/subscriptions/internal/config"
+	"github.com/mirrorblade/subscriptions/internal/handler"
+	"github.com/mirrorblade/subscriptions/internal/logging"
+	"github.com/mirrorblade/subscriptions/internal/metrics"
+	"github.com/mirrorblade/subscriptions/internal/ratelimit"
+	"github.com/mirrorblade/subscriptions/internal/tracing"
+	"github.com/mirrorblade/subscriptions/internal/worker"
+	"go.uber.org/zap"
+)
+
+func serve(config *config.Config) {
+	logger, level, err := logging.New(config.Log, !config.App.Production)
+	if err != nil {
+		panic(err)
+	}
+
+	defer logger.Sync()
+
+	zap.ReplaceGlobals(logger)
+
+	shutdownTracing, err := tracing.New(context.Background(), config.Tracing)
+	if err != nil {
+		panic(err)
+	}
+
+	pool, err := newPool(config)
+	if err != nil {
+		panic(err)
+	}
+	defer pool.Close()
+
+	if bypass, err := bypassesRLS(pool); err != nil {
+		logger.Warn("check row-level security", zap.Error(err))
+	} else if bypass {
+		logger.Warn("the database role bypasses row-level security, tenants are only isolated by queries", zap.String("role", config.Database.User))
+	}
+
+	metrics := metrics.New()
+
+	if err := metrics.RegisterPool(pool); err != nil {
+		panic(err)
+	}
+
+	service, err := newService(pool, config, metrics)
+	if err != nil {
+		panic(err)
+	}
+
+	authenticator, err := auth.New(config.Server.Auth)
+	if err != nil {
+		panic(err)
+	}
+
+	limiter, err := ratelimit.New(ratelimit.NewMemoryStore(), config.Server.RateLimit)
+	if err != nil {
+		panic(err)
+	}
+
+	handler := handler.New(service, authenticator, limiter, metrics, logger, level, &config.Server)
+	handler.Init()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+
+	purge := worker.NewPurge(service, logger, &config.Purge)
+	go purge.Run(ctx)
+
+	go func() {
+		if err := handler.Start(); err != nil && err != http.ErrServerClosed {
+			stop()
+			panic("shutting down the server")
+		}
+	}()
+
+	<-ctx.Done()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := handler.Shutdown(ctx); err != nil {
+		panic(err)
+	}
+
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Error("shutdown of tracing", zap.Error(err))
+	}
+}
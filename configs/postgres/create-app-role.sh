@@ -0,0 +1,16 @@
+#!/bin/sh
+# Creates the role the application connects as. It does not own the tables
+# and can not bypass row-level security, so the tenant policies apply to it.
+# The tables are created later by the migrations of POSTGRES_USER, which
+# grant the role access to them by default.
+set -e
+
+psql -v ON_ERROR_STOP=1 -v app_user="$APP_USER" -v app_password="$APP_PASSWORD" \
+	--username "$POSTGRES_USER" --dbname "$POSTGRES_DB" <<-'EOSQL'
+	CREATE ROLE :"app_user" LOGIN PASSWORD :'app_password' NOSUPERUSER NOBYPASSRLS NOCREATEDB NOCREATEROLE;
+	GRANT USAGE ON SCHEMA public TO :"app_user";
+	GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO :"app_user";
+	GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO :"app_user";
+	ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO :"app_user";
+	ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT USAGE, SELECT ON SEQUENCES TO :"app_user";
+EOSQL
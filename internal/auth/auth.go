@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/mirrorblade/subscriptions/internal/config"
+	"github.com/mirrorblade/subscriptions/internal/domain"
+)
+
+var ErrNoKeys = errors.New("neither a secret nor a JWKS file is configured")
+
+// claims are the claims of a token. The subject is the ID of the user the
+// token acts for, and scope is a space-separated list of scopes. Tokens
+// without scopes may read and write. Tokens with a tenant ID only act within
+// that tenant.
+type claims struct {
+	jwt.RegisteredClaims
+
+	Scope string   `json:"scope,omitempty"`
+	Roles []string `json:"roles,omitempty"`
+
+	TenantID string `json:"tenant_id,omitempty"`
+}
+
+type Authenticator struct {
+	secret []byte
+	keys   *keySet
+
+	parser *jwt.Parser
+}
+
+func New(config config.Auth) (*Authenticator, error) {
+	authenticator := &Authenticator{
+		secret: []byte(config.Secret),
+	}
+
+	methods := []string{}
+
+	if config.Secret != "" {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+
+	if config.JWKS != "" {
+		keys, err := loadKeySet(config.JWKS)
+		if err != nil {
+			return nil, fmt.Errorf("load JWKS: %w", err)
+		}
+
+		authenticator.keys = keys
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+
+	if len(methods) == 0 {
+		return nil, ErrNoKeys
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(config.Leeway),
+	}
+
+	if config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(config.Issuer))
+	}
+
+	if config.Audience != "" {
+		options = append(options, jwt.WithAudience(config.Audience))
+	}
+
+	authenticator.parser = jwt.NewParser(options...)
+
+	return authenticator, nil
+}
+
+// Authenticate verifies token and returns the principal it was issued to.
+func (a *Authenticator) Authenticate(token string) (domain.Principal, error) {
+	claims := new(claims)
+
+	if _, err := a.parser.ParseWithClaims(token, claims, a.key); err != nil {
+		return domain.Principal{}, fmt.Errorf("%w: %w", domain.ErrInvalidToken, err)
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil || userID == uuid.Nil {
+		return domain.Principal{}, fmt.Errorf("%w: subject is not a user ID", domain.ErrInvalidToken)
+	}
+
+	scopes := strings.Fields(claims.Scope)
+	if len(scopes) == 0 {
+		scopes = []string{domain.ScopeRead, domain.ScopeWrite}
+	}
+
+	return domain.Principal{
+		UserID:   userID,
+		Scopes:   scopes,
+		Roles:    claims.Roles,
+		TenantID: claims.TenantID,
+	}, nil
+}
+
+func (a *Authenticator) key(token *jwt.Token) (any, error) {
+	switch token.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		return a.secret, nil
+	case jwt.SigningMethodRS256.Alg():
+		kid, _ := token.Header["kid"].(string)
+
+		return a.keys.get(kid)
+	}
+
+	return nil, jwt.ErrTokenUnverifiable
+}
@@ -0,0 +1,3 @@
+// Package auth provides functionality for authenticating callers with JWT
+// bearer tokens
+package auth
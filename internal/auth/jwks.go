@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+var ErrKeyNotFound = errors.New("key was not found")
+
+// jwk is an RSA public key of a JWKS file (RFC 7517).
+type jwk struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	Alg     string `json:"alg"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+}
+
+// keySet holds the RS256 keys of a JWKS file by their IDs.
+type keySet struct {
+	keys map[string]*rsa.PublicKey
+}
+
+func loadKeySet(path string) (*keySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Keys []jwk `json:"keys"`
+	}
+
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	set := &keySet{
+		keys: map[string]*rsa.PublicKey{},
+	}
+
+	for i, key := range file.Keys {
+		// Keys for other algorithms or for encryption are not used.
+		if key.KeyType != "RSA" || (key.Use != "" && key.Use != "sig") || (key.Alg != "" && key.Alg != "RS256") {
+			continue
+		}
+
+		publicKey, err := key.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("key %d: %w", i, err)
+		}
+
+		set.keys[key.KeyID] = publicKey
+	}
+
+	if len(set.keys) == 0 {
+		return nil, errors.New("no RS256 signing keys")
+	}
+
+	return set, nil
+}
+
+func (k jwk) publicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("modulus: %w", err)
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("exponent: %w", err)
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+	if len(n) == 0 || !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+		return nil, errors.New("key is not valid")
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(exponent.Int64()),
+	}, nil
+}
+
+// get returns the key with the ID kid. Tokens without a key ID can only be
+// verified if the set has a single key.
+func (s *keySet) get(kid string) (*rsa.PublicKey, error) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, nil
+		}
+	}
+
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+
+	return key, nil
+}
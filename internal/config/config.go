@@ -16,6 +16,33 @@ type (
 		Production bool `koanf:"production"`
 	}
 
+	// Log configures the logger. Entries of Level and above are written to
+	// every output: stdout, stderr or file, in the json or console Encoding.
+	// The file is rotated when it grows over MaxSize megabytes, and rotated
+	// files are removed after MaxAge, rounded up to whole days, or when there
+	// are more than MaxBackups of them; a zero MaxAge keeps them regardless
+	// of age. Within each Tick, Sampling keeps the first Initial entries with
+	// the same level and message and then every Thereafter-th one.
+	Log struct {
+		Level    string   `koanf:"level"`
+		Encoding string   `koanf:"encoding"`
+		Outputs  []string `koanf:"outputs"`
+
+		File struct {
+			Path       string        `koanf:"path"`
+			MaxSize    int           `koanf:"maxsize"`
+			MaxBackups int           `koanf:"maxbackups"`
+			MaxAge     time.Duration `koanf:"maxage"`
+			Compress   bool          `koanf:"compress"`
+		} `koanf:"file"`
+
+		Sampling struct {
+			Initial    int           `koanf:"initial"`
+			Thereafter int           `koanf:"thereafter"`
+			Tick       time.Duration `koanf:"tick"`
+		} `koanf:"sampling"`
+	}
+
 	Database struct {
 		Name     string `koanf:"name"`
 		Host     string `koanf:"host"`
@@ -24,10 +51,62 @@ type (
 		Password string `koanf:"password"`
 	}
 
+	// Auth configures the keys bearer tokens are verified with. HS256 tokens
+	// are signed with Secret and RS256 tokens with a key of the JWKS file.
+	Auth struct {
+		Secret   string        `koanf:"secret"`
+		JWKS     string        `koanf:"jwks"`
+		Issuer   string        `koanf:"issuer"`
+		Audience string        `koanf:"audience"`
+		Leeway   time.Duration `koanf:"leeway"`
+	}
+
+	// RateLimit configures the token buckets that throttle clients. Every
+	// client may make Requests per Period in bursts of up to Burst requests,
+	// with separate limits for Routes. Every IP is also limited by IP before
+	// its requests are authenticated. Zero requests disable a limit.
+	RateLimit struct {
+		Requests int           `koanf:"requests"`
+		Period   time.Duration `koanf:"period"`
+		Burst    int           `koanf:"burst"`
+		Routes   []RouteLimit  `koanf:"routes"`
+
+		IP struct {
+			Requests int           `koanf:"requests"`
+			Period   time.Duration `koanf:"period"`
+			Burst    int           `koanf:"burst"`
+		} `koanf:"ip"`
+	}
+
+	// RouteLimit is the limit of the route of Method and Path, where Path is
+	// the route pattern, such as /rest/subscriptions/:id.
+	RouteLimit struct {
+		Method   string        `koanf:"method"`
+		Path     string        `koanf:"path"`
+		Requests int           `koanf:"requests"`
+		Period   time.Duration `koanf:"period"`
+		Burst    int           `koanf:"burst"`
+	}
+
 	Server struct {
 		Host string `koanf:"host"`
 		Port string `koanf:"port"`
 
+		Auth Auth `koanf:"auth"`
+
+		RateLimit RateLimit `koanf:"ratelimit"`
+
+		// Tenancy configures how the tenant of a request is resolved when
+		// its credentials do not bind it to one.
+		Tenancy struct {
+			Header  string `koanf:"header"`
+			Default string `koanf:"default"`
+		} `koanf:"tenancy"`
+
+		Idempotency struct {
+			TTL time.Duration `koanf:"ttl"`
+		} `koanf:"idempotency"`
+
 		CORS struct {
 			AllowOrigins     []string      `koanf:"allow_origins"`
 			AllowCredentials bool          `koanf:"allow_credentials"`
@@ -38,10 +117,37 @@ type (
 		}
 	}
 
+	Purge struct {
+		Retention time.Duration `koanf:"retention"`
+		Interval  time.Duration `koanf:"interval"`
+	}
+
+	Import struct {
+		BatchSize int `koanf:"batch_size"`
+	}
+
+	RBAC struct {
+		Policy string `koanf:"policy"`
+	}
+
+	// Tracing configures the export of traces: Exporter is none, otlp or
+	// stdout, Endpoint is the URL of the OTLP/HTTP collector and Ratio is
+	// the share of new traces that are sampled, from 0 to 1.
+	Tracing struct {
+		Exporter string  `koanf:"exporter"`
+		Endpoint string  `koanf:"endpoint"`
+		Ratio    float64 `koanf:"ratio"`
+	}
+
 	Config struct {
 		App      App
+		Log      Log
 		Database Database
 		Server   Server
+		Purge    Purge
+		Import   Import
+		RBAC     RBAC
+		Tracing  Tracing
 	}
 )
 
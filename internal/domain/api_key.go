@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+	// ScopeAdmin allows access to the subscriptions of every user.
+	ScopeAdmin = "admin"
+)
+
+// Scopes are the scopes API keys can be given. The admin scope includes the
+// other ones.
+var Scopes = []string{ScopeRead, ScopeWrite, ScopeAdmin}
+
+// APIKey is a key of a service caller that does not act for a single user.
+// Only the SHA-256 hash of the key is stored, the prefix identifies it. The
+// key only works within its tenant.
+type APIKey struct {
+	ID        uuid.UUID  `json:"id"`
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix"`
+	Hash      string     `json:"-"`
+	TenantID  string     `json:"tenant_id"`
+	Scopes    []string   `json:"scopes"`
+	Roles     []string   `json:"roles"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Active reports whether the key can be used at now.
+func (k APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
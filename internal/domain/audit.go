@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditAction string
+
+const (
+	AuditActionCreate  AuditAction = "create"
+	AuditActionUpdate  AuditAction = "update"
+	AuditActionDelete  AuditAction = "delete"
+	AuditActionRestore AuditAction = "restore"
+	AuditActionPurge   AuditAction = "purge"
+)
+
+type AuditRecord struct {
+	ID             int64           `json:"id"`
+	SubscriptionID uuid.UUID       `json:"subscription_id"`
+	TenantID       string          `json:"-"`
+	Action         AuditAction     `json:"action"`
+	Actor          string          `json:"actor"`
+	RequestID      string          `json:"request_id,omitempty"`
+	Before         json.RawMessage `json:"before,omitempty"`
+	After          json.RawMessage `json:"after,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
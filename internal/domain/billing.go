@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+type BillingPeriod string
+
+const (
+	BillingPeriodWeekly    BillingPeriod = "weekly"
+	BillingPeriodMonthly   BillingPeriod = "monthly"
+	BillingPeriodQuarterly BillingPeriod = "quarterly"
+	BillingPeriodYearly    BillingPeriod = "yearly"
+)
+
+func (p BillingPeriod) Valid() bool {
+	switch p {
+	case BillingPeriodWeekly, BillingPeriodMonthly, BillingPeriodQuarterly, BillingPeriodYearly:
+		return true
+	}
+
+	return false
+}
+
+// PerYear returns how many times per year a subscription with this billing
+// period is charged.
+func (p BillingPeriod) PerYear() int64 {
+	switch p {
+	case BillingPeriodWeekly:
+		return 52
+	case BillingPeriodQuarterly:
+		return 4
+	case BillingPeriodYearly:
+		return 1
+	default:
+		return 12
+	}
+}
+
+// Normalize converts a price charged every p into the equivalent price
+// charged every target.
+func (p BillingPeriod) Normalize(price int64, target BillingPeriod) int64 {
+	return int64(math.Round(float64(price) * float64(p.PerYear()) / float64(target.PerYear())))
+}
+
+// Charges counts the billing dates of a subscription started at start that
+// fall into the months from "from" to "to", both inclusive. The first
+// billing date is start, so none fall before it.
+func (p BillingPeriod) Charges(start, from, to time.Time) int64 {
+	if to.Before(from) {
+		return 0
+	}
+
+	if p == BillingPeriodWeekly {
+		const week = 7 * 24 * time.Hour
+
+		first := max(ceilDiv(int64(from.Sub(start)), int64(week)), 0)
+		last := floorDiv(int64(to.AddDate(0, 1, 0).Sub(start))-1, int64(week))
+
+		return max(last-first+1, 0)
+	}
+
+	var step int64
+	switch p {
+	case BillingPeriodQuarterly:
+		step = 3
+	case BillingPeriodYearly:
+		step = 12
+	default:
+		step = 1
+	}
+
+	first := max(ceilDiv(monthsBetween(start, from), step), 0)
+	last := floorDiv(monthsBetween(start, to), step)
+
+	return max(last-first+1, 0)
+}
+
+func monthsBetween(from, to time.Time) int64 {
+	return int64(to.Year()-from.Year())*12 + int64(to.Month()-from.Month())
+}
+
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+
+	return q
+}
+
+func ceilDiv(a, b int64) int64 {
+	return -floorDiv(-a, b)
+}
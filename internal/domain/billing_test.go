@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func month(year int, month time.Month) time.Time {
+	return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func TestBillingPeriodCharges(t *testing.T) {
+	tests := []struct {
+		name   string
+		period BillingPeriod
+		start  time.Time
+		from   time.Time
+		to     time.Time
+		want   int64
+	}{
+		{"monthly year", BillingPeriodMonthly, month(2025, 1), month(2025, 1), month(2025, 12), 12},
+		{"monthly single month", BillingPeriodMonthly, month(2025, 1), month(2025, 3), month(2025, 3), 1},
+		{"monthly reversed range", BillingPeriodMonthly, month(2025, 1), month(2025, 6), month(2025, 3), 0},
+		{"monthly range from before start", BillingPeriodMonthly, month(2025, 3), month(2025, 1), month(2025, 6), 4},
+		{"monthly range before start", BillingPeriodMonthly, month(2025, 6), month(2025, 1), month(2025, 3), 0},
+
+		{"weekly first month", BillingPeriodWeekly, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), month(2025, 1), month(2025, 1), 5},
+		{"weekly later month", BillingPeriodWeekly, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), month(2025, 2), month(2025, 2), 4},
+		{"weekly year", BillingPeriodWeekly, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), month(2025, 1), month(2025, 12), 53},
+		{"weekly start in month", BillingPeriodWeekly, time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), month(2025, 1), month(2025, 1), 3},
+		{"weekly range before start", BillingPeriodWeekly, month(2025, 6), month(2025, 1), month(2025, 5), 0},
+
+		{"quarterly year", BillingPeriodQuarterly, month(2025, 1), month(2025, 1), month(2025, 12), 4},
+		{"quarterly between dates", BillingPeriodQuarterly, month(2025, 1), month(2025, 2), month(2025, 3), 0},
+		{"quarterly billing month", BillingPeriodQuarterly, month(2025, 1), month(2025, 4), month(2025, 4), 1},
+		{"quarterly range across date", BillingPeriodQuarterly, month(2025, 1), month(2025, 2), month(2025, 4), 1},
+
+		{"yearly anniversary in range", BillingPeriodYearly, month(2024, 5), month(2025, 1), month(2025, 12), 1},
+		{"yearly between anniversaries", BillingPeriodYearly, month(2024, 5), month(2025, 6), month(2026, 4), 0},
+		{"yearly both ends", BillingPeriodYearly, month(2024, 5), month(2024, 5), month(2026, 5), 3},
+	}
+
+	for _, tt := range tests {
+		if got := tt.period.Charges(tt.start, tt.from, tt.to); got != tt.want {
+			t.Errorf("%s: Charges = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBillingPeriodNormalize(t *testing.T) {
+	tests := []struct {
+		period BillingPeriod
+		price  int64
+		target BillingPeriod
+		want   int64
+	}{
+		{BillingPeriodMonthly, 399, BillingPeriodMonthly, 399},
+		{BillingPeriodMonthly, 300, BillingPeriodYearly, 3600},
+		{BillingPeriodMonthly, 100, BillingPeriodWeekly, 23},
+		{BillingPeriodWeekly, 100, BillingPeriodMonthly, 433},
+		{BillingPeriodWeekly, 100, BillingPeriodYearly, 5200},
+		{BillingPeriodQuarterly, 900, BillingPeriodMonthly, 300},
+		{BillingPeriodQuarterly, 1000, BillingPeriodYearly, 4000},
+		{BillingPeriodYearly, 1200, BillingPeriodMonthly, 100},
+		{BillingPeriodYearly, 6, BillingPeriodMonthly, 1},
+		{BillingPeriodYearly, 5, BillingPeriodMonthly, 0},
+	}
+
+	for _, tt := range tests {
+		if got := tt.period.Normalize(tt.price, tt.target); got != tt.want {
+			t.Errorf("%d %s as %s: Normalize = %d, want %d", tt.price, tt.period, tt.target, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"context"
+	"regexp"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+type contextKey int
+
+const (
+	actorKey contextKey = iota
+	requestIDKey
+	principalKey
+	tenantKey
+)
+
+// AllTenants is the tenant of background jobs that work on the data of every
+// tenant. It is never a valid tenant ID, so no data can belong to it.
+const AllTenants = "*"
+
+// SystemActor is recorded as the actor of changes made without a caller,
+// such as background jobs.
+const SystemActor = "system"
+
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+func ActorFromContext(ctx context.Context) string {
+	actor, ok := ctx.Value(actorKey).(string)
+	if !ok || actor == "" {
+		return SystemActor
+	}
+
+	return actor
+}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+
+	return requestID
+}
+
+// Principal is the authenticated caller of a request: either a user or a
+// service with an API key. Roles decide which operations it may perform,
+// and a principal with a tenant ID is bound to that tenant.
+type Principal struct {
+	UserID   uuid.UUID
+	APIKeyID uuid.UUID
+	TenantID string
+	Scopes   []string
+	Roles    []string
+}
+
+// SystemPrincipal acts for background jobs and command line tools, which are
+// trusted with the subscriptions of every user.
+var SystemPrincipal = Principal{Scopes: []string{ScopeAdmin}}
+
+// HasScope reports whether the principal has scope. The admin scope
+// includes every other scope.
+func (p Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope) || p.Admin()
+}
+
+func (p Principal) Admin() bool {
+	return slices.Contains(p.Scopes, ScopeAdmin)
+}
+
+// AllUsers reports whether the principal may access the subscriptions of
+// every user regardless of its roles: admins may, and services are limited
+// by the scopes of their API keys instead. Other users depend on the roles
+// the policy lets access every user.
+func (p Principal) AllUsers() bool {
+	return p.Admin() || p.APIKeyID != uuid.Nil
+}
+
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey).(Principal)
+
+	return principal, ok
+}
+
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey, tenantID)
+}
+
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenantID, ok := ctx.Value(tenantKey).(string)
+
+	return tenantID, ok && tenantID != ""
+}
+
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// ValidTenantID reports whether tenantID can name a tenant: lowercase letters,
+// digits, hyphens and underscores, up to 63 characters.
+func ValidTenantID(tenantID string) bool {
+	return tenantIDPattern.MatchString(tenantID)
+}
@@ -1,12 +1,86 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"strings"
+)
 
 var (
 	ErrSubscriptionNotFound = errors.New("subscription was not found")
+	ErrSubscriptionActive   = errors.New("subscription is not deleted")
 	ErrUserNotFound         = errors.New("user was not found")
+	ErrUserRequired         = errors.New("user is required")
 	ErrInvalidID            = errors.New("id is not valid")
+	ErrInvalidServiceName   = errors.New("service name is not valid")
 	ErrNoUpdateParameters   = errors.New("no update paramaters was chose")
 	ErrInvalidPrice         = errors.New("price is not valid")
 	ErrInvalidDate          = errors.New("date is not valid")
+	ErrInvalidBillingPeriod = errors.New("billing period is not valid")
+	ErrInvalidCurrency      = errors.New("currency is not valid")
+	ErrCurrencyRequired     = errors.New("currency is required for subscriptions in different currencies")
+	ErrExchangeRateNotFound = errors.New("exchange rate was not found")
+	ErrInvalidCursor        = errors.New("cursor is not valid")
+	ErrInvalidSort          = errors.New("sort field is not valid")
+	ErrVersionRequired      = errors.New("subscription version is required")
+	ErrVersionMismatch      = errors.New("subscription version does not match")
+	ErrInvalidValue         = errors.New("value is not valid")
+	ErrInvalidBody          = errors.New("request body is not valid")
+	ErrBodyTooLarge         = errors.New("request body is too large")
+
+	ErrIdempotencyKeyReused     = errors.New("idempotency key was used for another request")
+	ErrIdempotencyKeyInProgress = errors.New("request with the idempotency key is in progress")
+
+	ErrUnauthenticated   = errors.New("authentication is required")
+	ErrInvalidToken      = errors.New("token is not valid")
+	ErrForbidden         = errors.New("access is forbidden")
+	ErrInsufficientScope = errors.New("credentials do not have the required scope")
+	ErrInvalidAPIKey     = errors.New("api key is not valid")
+	ErrAPIKeyNotFound    = errors.New("api key was not found")
+	ErrInvalidScope      = errors.New("scope is not valid")
+	ErrOperationDenied   = errors.New("roles do not allow the operation")
+	ErrInvalidTenant     = errors.New("tenant is not valid")
+	ErrTenantRequired    = errors.New("tenant is required")
+
+	ErrRateLimited = errors.New("rate limit was exceeded")
+
+	ErrInvalidBatch       = errors.New("batch is not valid")
+	ErrBatchAborted       = errors.New("batch was aborted because of another operation")
+	ErrDuplicateOperation = errors.New("subscription is changed by another operation of the batch")
 )
+
+// FieldError is an error caused by the value of a single field of a request.
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError reports every invalid field of a request at once.
+type ValidationError struct {
+	Violations []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.Error()
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Violations))
+	for i, violation := range e.Violations {
+		errs[i] = violation
+	}
+
+	return errs
+}
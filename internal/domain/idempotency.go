@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"net/http"
+	"time"
+)
+
+type IdempotentResponse struct {
+	Status  int         `json:"status"`
+	Headers http.Header `json:"headers"`
+	Body    []byte      `json:"body"`
+}
+
+// IdempotencyKey is a client-supplied key of a request. Its response is nil
+// while the first request with the key is still being processed.
+type IdempotencyKey struct {
+	Key         string
+	RequestHash string
+	Response    *IdempotentResponse
+	ExpiresAt   time.Time
+}
@@ -0,0 +1,16 @@
+package domain
+
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+// ImportReport summarizes an import. In a dry run valid rows are counted
+// but not imported.
+type ImportReport struct {
+	DryRun   bool             `json:"dry_run"`
+	Total    int              `json:"total"`
+	Valid    int              `json:"valid"`
+	Imported int              `json:"imported"`
+	Errors   []ImportRowError `json:"errors"`
+}
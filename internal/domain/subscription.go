@@ -7,10 +7,41 @@ import (
 )
 
 type Subscription struct {
-	ID          uuid.UUID  `json:"id"`
-	ServiceName string     `json:"service_name"`
-	Price       int64      `json:"price"`
-	UserID      uuid.UUID  `json:"user_id"`
-	StartDate   time.Time  `json:"start_date"`
-	EndDate     *time.Time `json:"end_date,omitempty"`
+	ID            uuid.UUID     `json:"id"`
+	TenantID      string        `json:"-"`
+	ServiceName   string        `json:"service_name"`
+	Price         int64         `json:"price"`
+	Currency      Currency      `json:"currency"`
+	BillingPeriod BillingPeriod `json:"billing_period"`
+	UserID        uuid.UUID     `json:"user_id"`
+	StartDate     time.Time     `json:"start_date"`
+	EndDate       *time.Time    `json:"end_date,omitempty"`
+	Version       int64         `json:"version"`
+	DeletedAt     *time.Time    `json:"deleted_at,omitempty"`
+}
+
+type SubscriptionList struct {
+	Subscriptions []Subscription `json:"subscriptions"`
+	NextCursor    string         `json:"next_cursor,omitempty"`
+}
+
+type SubscriptionSearchResult struct {
+	Subscriptions []Subscription `json:"subscriptions"`
+	Total         int64          `json:"total"`
+}
+
+type SubscriptionCost struct {
+	Subscription Subscription `json:"subscription"`
+	Charges      int64        `json:"charges"`
+	ExchangeRate float64      `json:"exchange_rate"`
+	Cost         int64        `json:"cost"`
+	PeriodPrice  int64        `json:"period_price"`
+}
+
+type PriceSum struct {
+	Currency      Currency           `json:"currency"`
+	Total         int64              `json:"total"`
+	Period        BillingPeriod      `json:"period"`
+	PeriodTotal   int64              `json:"period_total"`
+	Subscriptions []SubscriptionCost `json:"subscriptions"`
 }
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mirrorblade/subscriptions/internal/domain"
+	"github.com/mirrorblade/subscriptions/internal/logging"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type logLevel struct {
+	Level string `json:"level"`
+}
+
+func (h *Handler) initAdmin() {
+	group := h.router.Group("/admin", h.rateLimitIP, h.authenticate, h.admin)
+
+	group.GET("/log/level", h.getLogLevel)
+	group.PUT("/log/level", h.setLogLevel)
+}
+
+// admin only lets through requests of principals with the admin scope.
+func (h *Handler) admin(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		principal, _ := domain.PrincipalFromContext(c.Request().Context())
+		if !principal.Admin() {
+			return domain.ErrInsufficientScope
+		}
+
+		return next(c)
+	}
+}
+
+func (h *Handler) getLogLevel(c echo.Context) error {
+	return c.JSON(http.StatusOK, logLevel{Level: h.level.Level().String()})
+}
+
+// setLogLevel changes the level of the logger until the service restarts.
+func (h *Handler) setLogLevel(c echo.Context) error {
+	body := new(logLevel)
+
+	if err := c.Bind(body); err != nil {
+		return fmt.Errorf("%w: %w", domain.ErrInvalidBody, err)
+	}
+
+	level, err := zapcore.ParseLevel(body.Level)
+	if body.Level == "" || err != nil {
+		return &domain.FieldError{Field: "level", Err: domain.ErrInvalidValue}
+	}
+
+	logging.FromContext(c.Request().Context()).Warn("log level changed",
+		zap.Stringer("from", h.level.Level()), zap.Stringer("to", level))
+
+	h.level.SetLevel(level)
+
+	return c.JSON(http.StatusOK, logLevel{Level: level.String()})
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mirrorblade/subscriptions/internal/domain"
+)
+
+const headerAPIKey = "X-API-Key"
+
+// authenticate verifies the API key or the bearer token of the request and
+// stores the principal it was issued to in the request context. Changes
+// made by the request are attributed to the user of the token or the key.
+func (h *Handler) authenticate(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var (
+			principal domain.Principal
+			actor     string
+		)
+
+		if key := c.Request().Header.Get(headerAPIKey); key != "" {
+			var err error
+
+			principal, err = h.service.APIKeys.Authenticate(c.Request().Context(), key)
+			if err != nil {
+				return err
+			}
+
+			actor = "api_key:" + principal.APIKeyID.String()
+		} else {
+			scheme, token, ok := strings.Cut(c.Request().Header.Get(echo.HeaderAuthorization), " ")
+			if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="subscriptions"`)
+
+				return domain.ErrUnauthenticated
+			}
+
+			var err error
+
+			principal, err = h.authenticator.Authenticate(strings.TrimSpace(token))
+			if err != nil {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="subscriptions", error="invalid_token"`)
+
+				return err
+			}
+
+			actor = principal.UserID.String()
+		}
+
+		context := domain.WithPrincipal(c.Request().Context(), principal)
+		context = domain.WithActor(context, actor)
+
+		c.SetRequest(c.Request().WithContext(context))
+
+		return next(c)
+	}
+}
@@ -3,13 +3,25 @@ package handler
 import (
 	"context"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/microcosm-cc/bluemonday"
+	"github.com/mirrorblade/subscriptions/internal/auth"
 	"github.com/mirrorblade/subscriptions/internal/config"
+	"github.com/mirrorblade/subscriptions/internal/domain"
+	"github.com/mirrorblade/subscriptions/internal/handler/problem"
 	"github.com/mirrorblade/subscriptions/internal/handler/rest"
+	"github.com/mirrorblade/subscriptions/internal/logging"
+	"github.com/mirrorblade/subscriptions/internal/metrics"
+	"github.com/mirrorblade/subscriptions/internal/ratelimit"
 	"github.com/mirrorblade/subscriptions/internal/service"
+	"github.com/mirrorblade/subscriptions/internal/tracing"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -17,27 +29,53 @@ type Handler struct {
 	router  *echo.Echo
 	service *service.Service
 
+	authenticator *auth.Authenticator
+	limiter       *ratelimit.Limiter
+	metrics       *metrics.Metrics
+
 	logger *zap.Logger
+	level  zap.AtomicLevel
 
 	config *config.Server
 }
 
-func New(service *service.Service, logger *zap.Logger, config *config.Server) *Handler {
+func New(service *service.Service, authenticator *auth.Authenticator, limiter *ratelimit.Limiter, metrics *metrics.Metrics, logger *zap.Logger, level zap.AtomicLevel, config *config.Server) *Handler {
 	return &Handler{
-		service: service,
-		logger:  logger,
-		config:  config,
+		service:       service,
+		authenticator: authenticator,
+		limiter:       limiter,
+		metrics:       metrics,
+		logger:        logger,
+		level:         level,
+		config:        config,
 	}
 }
 
 func (h *Handler) Init() {
 	h.router = echo.New()
 
+	h.router.HTTPErrorHandler = h.handleError
+
+	// Client IPs attribute unauthenticated changes and key rate limits, so
+	// X-Forwarded-For is only trusted when set by a proxy on a private
+	// network rather than taken from any client.
+	h.router.IPExtractor = echo.ExtractIPFromXFFHeader()
+
+	h.router.Use(h.observe)
+
+	h.router.Use(otelecho.Middleware(tracing.ServiceName, otelecho.WithSkipper(func(c echo.Context) bool {
+		return c.Path() == "/metrics" || c.Path() == "/health"
+	})))
+
+	h.router.Use(h.requestMetadata)
+
 	h.router.Use(middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
 		LogURI:      true,
 		LogMethod:   true,
 		LogStatus:   true,
 		LogRemoteIP: true,
+		LogError:    true,
+		HandleError: true,
 		LogValuesFunc: func(c echo.Context, v middleware.RequestLoggerValues) error {
 			fields := []zap.Field{
 				zap.String("uri", v.URI),
@@ -46,23 +84,20 @@ func (h *Handler) Init() {
 				zap.String("ip", v.RemoteIP),
 			}
 
-			if v.Status < 400 {
-				h.logger.Info("request", fields...)
-			} else if v.Status < 500 {
-				errorMessage, ok := c.Get("error").(error)
-				if ok {
-					fields = append(fields, zap.Error(errorMessage))
-				}
+			if v.Error != nil {
+				fields = append(fields, zap.Error(v.Error))
+			} else if errorMessage, ok := c.Get("error").(error); ok {
+				fields = append(fields, zap.Error(errorMessage))
+			}
 
-				h.logger.Warn("request", fields...)
+			logger := logging.FromContext(c.Request().Context())
 
+			if v.Status < 400 {
+				logger.Info("request", fields...)
+			} else if v.Status < 500 {
+				logger.Warn("request", fields...)
 			} else {
-				errorMessage, ok := c.Get("error").(error)
-				if ok {
-					fields = append(fields, zap.Error(errorMessage))
-				}
-
-				h.logger.Error("request", fields...)
+				logger.Error("request", fields...)
 			}
 
 			return nil
@@ -74,6 +109,7 @@ func (h *Handler) Init() {
 	corsConfig.AllowOrigins = h.config.CORS.AllowOrigins
 	corsConfig.AllowMethods = h.config.CORS.AllowMethods
 	corsConfig.AllowHeaders = h.config.CORS.AllowHeaders
+	corsConfig.ExposeHeaders = h.config.CORS.ExposeHeaders
 	corsConfig.AllowCredentials = h.config.CORS.AllowCredentials
 	corsConfig.MaxAge = int(h.config.CORS.MaxAge.Seconds())
 
@@ -83,7 +119,11 @@ func (h *Handler) Init() {
 
 	h.checkHealth()
 
+	h.router.GET("/metrics", echo.WrapHandler(h.metrics.Handler()))
+
 	h.initRest()
+
+	h.initAdmin()
 }
 
 func (h *Handler) Start() error {
@@ -95,12 +135,88 @@ func (h *Handler) Shutdown(context context.Context) error {
 }
 
 func (h *Handler) initRest() {
-	group := h.router.Group("/rest")
+	group := h.router.Group("/rest", h.rateLimitIP, h.authenticate, h.rateLimit, h.tenant)
 
 	handler := rest.New(h.service, bluemonday.UGCPolicy())
 	handler.Init(group)
 }
 
+// requestMetadata stores the caller, the request ID and a logger with the
+// request ID in the request context, so that changes made by the request can
+// be attributed to them and its log entries correlated. The caller is the
+// client IP until the request is authenticated. The request ID is taken from
+// the request if it is valid and generated otherwise, and is returned in the
+// response.
+func (h *Handler) requestMetadata(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+
+		requestID := c.Request().Header.Get(echo.HeaderXRequestID)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		c.Response().Header().Set(echo.HeaderXRequestID, requestID)
+
+		context := domain.WithActor(c.Request().Context(), c.RealIP())
+		context = domain.WithRequestID(context, requestID)
+
+		fields := []zap.Field{zap.String("request_id", requestID), logging.Latency(start)}
+		if span := trace.SpanContextFromContext(context); span.IsValid() {
+			fields = append(fields, zap.String("trace_id", span.TraceID().String()))
+		}
+
+		context = logging.WithLogger(context, h.logger.With(fields...))
+
+		c.SetRequest(c.Request().WithContext(context))
+
+		return next(c)
+	}
+}
+
+// maxRequestIDLength bounds the request IDs accepted from clients.
+const maxRequestIDLength = 128
+
+// validRequestID reports whether a request ID from a client can be used as
+// is: it is not empty, not too long and only has letters, digits, hyphens,
+// underscores, dots and colons, so it can not forge log entries.
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+
+	for _, r := range requestID {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_.:", r)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// handleError responds with the problem details of err, unless a response
+// was already sent.
+func (h *Handler) handleError(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	details := problem.New(err)
+	details.Instance = c.Request().URL.Path
+	details.RequestID = domain.RequestIDFromContext(c.Request().Context())
+
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(details.Status)
+	} else {
+		c.Response().Header().Set(echo.HeaderContentType, problem.MediaType)
+		err = c.JSON(details.Status, details)
+	}
+
+	if err != nil {
+		logging.FromContext(c.Request().Context()).Error("error response", zap.Error(err))
+	}
+}
+
 func (h *Handler) checkHealth() {
 	h.router.GET("/health", func(c echo.Context) error {
 		return c.JSON(http.StatusOK, map[string]string{
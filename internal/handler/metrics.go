@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// routeUnmatched labels requests that did not match a route, so that
+// arbitrary paths do not create new series.
+const routeUnmatched = "unmatched"
+
+// observe records the status and the duration of every request by its route
+// pattern. It runs before the other middleware, so the response has been
+// sent by the time the request is recorded.
+func (h *Handler) observe(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+
+		err := next(c)
+
+		route := c.Path()
+		if route == "" {
+			route = routeUnmatched
+		}
+
+		h.metrics.ObserveRequest(c.Request().Method, route, c.Response().Status, time.Since(start))
+
+		return err
+	}
+}
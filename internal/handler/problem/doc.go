@@ -0,0 +1,2 @@
+// Package problem provides functionality for problem details (RFC 9457) responses
+package problem
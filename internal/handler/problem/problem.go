@@ -0,0 +1,169 @@
+package problem
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mirrorblade/subscriptions/internal/domain"
+	"github.com/mirrorblade/subscriptions/internal/importer"
+)
+
+const (
+	MediaType = "application/problem+json"
+
+	typePrefix = "urn:subscriptions:problem:"
+)
+
+// Details is a problem details object. Code is a stable identifier of the
+// problem that clients can rely on, and Field names the request field that
+// caused it, if any. Errors lists every invalid field of a request that
+// failed validation.
+type Details struct {
+	Type      string      `json:"type"`
+	Title     string      `json:"title"`
+	Status    int         `json:"status"`
+	Detail    string      `json:"detail,omitempty"`
+	Instance  string      `json:"instance,omitempty"`
+	Code      string      `json:"code"`
+	Field     string      `json:"field,omitempty"`
+	Errors    []Violation `json:"errors,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
+}
+
+// Violation describes a single invalid field.
+type Violation struct {
+	Field  string `json:"field"`
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+}
+
+type kind struct {
+	err    error
+	status int
+	code   string
+}
+
+// kinds maps errors to problems. The first matching error wins, so more
+// specific errors go first.
+var kinds = []kind{
+	{domain.ErrSubscriptionNotFound, http.StatusNotFound, "subscription_not_found"},
+	{domain.ErrUserNotFound, http.StatusNotFound, "user_not_found"},
+	{domain.ErrUserRequired, http.StatusBadRequest, "user_required"},
+	{domain.ErrSubscriptionActive, http.StatusConflict, "subscription_not_deleted"},
+	{domain.ErrInvalidID, http.StatusBadRequest, "invalid_id"},
+	{domain.ErrInvalidServiceName, http.StatusBadRequest, "invalid_service_name"},
+	{domain.ErrNoUpdateParameters, http.StatusBadRequest, "no_update_parameters"},
+	{domain.ErrInvalidPrice, http.StatusBadRequest, "invalid_price"},
+	{domain.ErrInvalidDate, http.StatusBadRequest, "invalid_date"},
+	{domain.ErrInvalidBillingPeriod, http.StatusBadRequest, "invalid_billing_period"},
+	{domain.ErrInvalidCurrency, http.StatusBadRequest, "invalid_currency"},
+	{domain.ErrCurrencyRequired, http.StatusBadRequest, "currency_required"},
+	{domain.ErrExchangeRateNotFound, http.StatusUnprocessableEntity, "exchange_rate_not_found"},
+	{domain.ErrInvalidCursor, http.StatusBadRequest, "invalid_cursor"},
+	{domain.ErrInvalidSort, http.StatusBadRequest, "invalid_sort"},
+	{domain.ErrVersionRequired, http.StatusPreconditionRequired, "version_required"},
+	{domain.ErrVersionMismatch, http.StatusPreconditionFailed, "version_mismatch"},
+	{domain.ErrInvalidValue, http.StatusBadRequest, "invalid_value"},
+	{domain.ErrInvalidBody, http.StatusBadRequest, "invalid_body"},
+	{domain.ErrBodyTooLarge, http.StatusRequestEntityTooLarge, "body_too_large"},
+	{domain.ErrUnauthenticated, http.StatusUnauthorized, "unauthenticated"},
+	{domain.ErrInvalidToken, http.StatusUnauthorized, "invalid_token"},
+	{domain.ErrForbidden, http.StatusForbidden, "forbidden"},
+	{domain.ErrInsufficientScope, http.StatusForbidden, "insufficient_scope"},
+	{domain.ErrOperationDenied, http.StatusForbidden, "operation_denied"},
+	{domain.ErrInvalidAPIKey, http.StatusUnauthorized, "invalid_api_key"},
+	{domain.ErrAPIKeyNotFound, http.StatusNotFound, "api_key_not_found"},
+	{domain.ErrInvalidScope, http.StatusBadRequest, "invalid_scope"},
+	{domain.ErrInvalidTenant, http.StatusBadRequest, "invalid_tenant"},
+	{domain.ErrTenantRequired, http.StatusBadRequest, "tenant_required"},
+	{domain.ErrRateLimited, http.StatusTooManyRequests, "rate_limited"},
+	{domain.ErrIdempotencyKeyReused, http.StatusUnprocessableEntity, "idempotency_key_reused"},
+	{domain.ErrIdempotencyKeyInProgress, http.StatusConflict, "idempotency_key_in_progress"},
+	{domain.ErrInvalidBatch, http.StatusBadRequest, "invalid_batch"},
+	{domain.ErrBatchAborted, http.StatusFailedDependency, "batch_aborted"},
+	{domain.ErrDuplicateOperation, http.StatusUnprocessableEntity, "duplicate_operation"},
+	{importer.ErrUnsupportedFormat, http.StatusUnsupportedMediaType, "unsupported_format"},
+	{importer.ErrInvalidFile, http.StatusBadRequest, "invalid_file"},
+}
+
+// New describes err as a problem. Errors that are not known are reported as
+// internal errors without details, so that they do not leak to clients.
+func New(err error) Details {
+	details := Details{
+		Status: http.StatusInternalServerError,
+		Code:   "internal_error",
+	}
+
+	var (
+		validationErr *domain.ValidationError
+		httpErr       *echo.HTTPError
+	)
+
+	if errors.As(err, &validationErr) {
+		return invalid(validationErr)
+	}
+
+	if kind, ok := lookup(err); ok {
+		details.Status, details.Code = kind.status, kind.code
+	} else if errors.As(err, &httpErr) {
+		details.Status = httpErr.Code
+		details.Code = strings.ReplaceAll(strings.ToLower(http.StatusText(httpErr.Code)), " ", "_")
+
+		if message, ok := httpErr.Message.(string); ok {
+			err = errors.New(message)
+		}
+	}
+
+	details.Type = typePrefix + details.Code
+	details.Title = http.StatusText(details.Status)
+
+	if details.Status < http.StatusInternalServerError {
+		details.Detail = err.Error()
+
+		var fieldErr *domain.FieldError
+		if errors.As(err, &fieldErr) {
+			details.Field = fieldErr.Field
+			details.Detail = fieldErr.Err.Error()
+		}
+	}
+
+	return details
+}
+
+func invalid(err *domain.ValidationError) Details {
+	details := Details{
+		Type:   typePrefix + "validation_failed",
+		Title:  http.StatusText(http.StatusBadRequest),
+		Status: http.StatusBadRequest,
+		Detail: "request has invalid fields",
+		Code:   "validation_failed",
+		Errors: make([]Violation, len(err.Violations)),
+	}
+
+	for i, violation := range err.Violations {
+		code := "invalid_value"
+		if kind, ok := lookup(violation.Err); ok {
+			code = kind.code
+		}
+
+		details.Errors[i] = Violation{
+			Field:  violation.Field,
+			Code:   code,
+			Detail: violation.Err.Error(),
+		}
+	}
+
+	return details
+}
+
+func lookup(err error) (kind, bool) {
+	for _, kind := range kinds {
+		if errors.Is(err, kind.err) {
+			return kind, true
+		}
+	}
+
+	return kind{}, false
+}
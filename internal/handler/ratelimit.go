@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/mirrorblade/subscriptions/internal/domain"
+	"github.com/mirrorblade/subscriptions/internal/logging"
+	"github.com/mirrorblade/subscriptions/internal/ratelimit"
+	"go.uber.org/zap"
+)
+
+const (
+	headerRateLimitLimit     = "RateLimit-Limit"
+	headerRateLimitRemaining = "RateLimit-Remaining"
+	headerRateLimitReset     = "RateLimit-Reset"
+	headerRetryAfter         = "Retry-After"
+)
+
+// rateLimitIP throttles the requests of every IP before they are
+// authenticated, so that requests without valid credentials are throttled
+// too. The RateLimit headers of the IP are only sent when it is throttled,
+// since the bucket of the client describes the requests it may make.
+func (h *Handler) rateLimitIP(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		result, ok, err := h.limiter.TakeIP(c.Request().Context(), c.RealIP())
+
+		return h.limit(c, next, result, ok && !result.Allowed, err)
+	}
+}
+
+// rateLimit throttles the requests of every authenticated client: the API
+// key, or the user of the token.
+func (h *Handler) rateLimit(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		result, ok, err := h.limiter.Take(c.Request().Context(), client(c), c.Request().Method, c.Path())
+
+		return h.limit(c, next, result, ok, err)
+	}
+}
+
+// limit reports the state of the bucket of result in RateLimit headers if
+// report is set, and rejects the request if it was not allowed. If the
+// limiter failed, the request is let through rather than rejected.
+func (h *Handler) limit(c echo.Context, next echo.HandlerFunc, result ratelimit.Result, report bool, err error) error {
+	if err != nil {
+		logging.FromContext(c.Request().Context()).Error("rate limit", zap.Error(err))
+
+		return next(c)
+	}
+
+	if !report {
+		return next(c)
+	}
+
+	header := c.Response().Header()
+	header.Set(headerRateLimitLimit, strconv.Itoa(result.Limit))
+	header.Set(headerRateLimitRemaining, strconv.Itoa(result.Remaining))
+	header.Set(headerRateLimitReset, strconv.Itoa(ceilSeconds(result.Reset)))
+
+	if !result.Allowed {
+		header.Set(headerRetryAfter, strconv.Itoa(ceilSeconds(result.RetryAfter)))
+
+		return domain.ErrRateLimited
+	}
+
+	return next(c)
+}
+
+func client(c echo.Context) string {
+	principal, _ := domain.PrincipalFromContext(c.Request().Context())
+
+	if principal.APIKeyID != uuid.Nil {
+		return "api_key:" + principal.APIKeyID.String()
+	}
+
+	return "user:" + principal.UserID.String()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/mirrorblade/subscriptions/internal/auth"
+	"github.com/mirrorblade/subscriptions/internal/config"
+	"github.com/mirrorblade/subscriptions/internal/metrics"
+	"github.com/mirrorblade/subscriptions/internal/ratelimit"
+	"go.uber.org/zap"
+)
+
+func TestRateLimitUnauthenticated(t *testing.T) {
+	server := &config.Server{}
+	server.RateLimit.Requests = 100
+	server.RateLimit.Period = time.Minute
+	server.RateLimit.IP.Requests = 3
+	server.RateLimit.IP.Period = time.Minute
+
+	authenticator, err := auth.New(config.Auth{Secret: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	limiter, err := ratelimit.New(ratelimit.NewMemoryStore(), server.RateLimit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := New(nil, authenticator, limiter, metrics.New(), zap.NewNop(), zap.NewAtomicLevel(), server)
+	h.Init()
+
+	tests := []struct {
+		name          string
+		authorization string
+		status        int
+	}{
+		{"no credentials", "", http.StatusUnauthorized},
+		{"invalid token", "Bearer invalid", http.StatusUnauthorized},
+		{"invalid token", "Bearer invalid", http.StatusUnauthorized},
+		{"invalid token after burst", "Bearer invalid", http.StatusTooManyRequests},
+		{"no credentials after burst", "", http.StatusTooManyRequests},
+	}
+
+	for i, tt := range tests {
+		request := httptest.NewRequest(http.MethodGet, "/rest/subscriptions/", nil)
+		request.RemoteAddr = "203.0.113.1:1234"
+		// The client can not escape its bucket by forging its IP.
+		request.Header.Set("X-Forwarded-For", "198.51.100."+strconv.Itoa(i))
+
+		if tt.authorization != "" {
+			request.Header.Set("Authorization", tt.authorization)
+		}
+
+		response := httptest.NewRecorder()
+		h.router.ServeHTTP(response, request)
+
+		if response.Code != tt.status {
+			t.Fatalf("%s: status = %d, want %d", tt.name, response.Code, tt.status)
+		}
+
+		if tt.status == http.StatusTooManyRequests && response.Header().Get(headerRetryAfter) == "" {
+			t.Errorf("%s: Retry-After is missing", tt.name)
+		}
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/rest/subscriptions/", nil)
+	request.RemoteAddr = "203.0.113.2:1234"
+
+	response := httptest.NewRecorder()
+	h.router.ServeHTTP(response, request)
+
+	if response.Code != http.StatusUnauthorized {
+		t.Errorf("other IP: status = %d, want %d", response.Code, http.StatusUnauthorized)
+	}
+}
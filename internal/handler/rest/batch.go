@@ -0,0 +1,177 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/mirrorblade/subscriptions/internal/domain"
+	"github.com/mirrorblade/subscriptions/internal/handler/problem"
+	"github.com/mirrorblade/subscriptions/internal/repository"
+)
+
+const (
+	batchModeAtomic     = "atomic"
+	batchModeBestEffort = "best_effort"
+)
+
+type batchBody struct {
+	Mode       string               `json:"mode"`
+	Operations []batchOperationBody `json:"operations"`
+}
+
+type batchOperationBody struct {
+	Op           string                     `json:"op"`
+	ID           string                     `json:"id,omitempty"`
+	Version      int64                      `json:"version,omitempty"`
+	Subscription *createSubscriptionBody    `json:"subscription,omitempty"`
+	Patch        map[string]json.RawMessage `json:"patch,omitempty"`
+}
+
+type batchResultBody struct {
+	Index        int                  `json:"index"`
+	Status       int                  `json:"status"`
+	Subscription *domain.Subscription `json:"subscription,omitempty"`
+	Error        *problem.Details     `json:"error,omitempty"`
+}
+
+func (h *Handler) batchSubscriptions(c echo.Context) error {
+	body := new(batchBody)
+	if err := c.Bind(body); err != nil {
+		return fmt.Errorf("%w: %w", domain.ErrInvalidBody, err)
+	}
+
+	if body.Mode == "" {
+		body.Mode = batchModeAtomic
+	}
+
+	if body.Mode != batchModeAtomic && body.Mode != batchModeBestEffort {
+		return &domain.FieldError{Field: "mode", Err: domain.ErrInvalidBatch}
+	}
+
+	operations := make([]repository.BatchOperation, 0, len(body.Operations))
+
+	for i := range body.Operations {
+		operation, err := h.batchOperation(c.Request().Context(), &body.Operations[i])
+		if err != nil {
+			return nestedFieldError(fmt.Sprintf("operations[%d]", i), err)
+		}
+
+		operations = append(operations, operation)
+	}
+
+	results, err := h.service.Subscriptions.Batch(c.Request().Context(), operations, body.Mode == batchModeAtomic)
+	if err != nil {
+		return err
+	}
+
+	response := make([]batchResultBody, len(results))
+	failed := []error{}
+
+	for i, result := range results {
+		response[i] = batchResultBody{
+			Index:        i,
+			Subscription: result.Subscription,
+		}
+
+		if result.Err != nil {
+			details := problem.New(result.Err)
+			response[i].Status = details.Status
+			response[i].Error = &details
+
+			if !errors.Is(result.Err, domain.ErrBatchAborted) {
+				failed = append(failed, fmt.Errorf("operation %d: %w", i, result.Err))
+			}
+
+			continue
+		}
+
+		switch operations[i].Type {
+		case repository.BatchOperationCreate:
+			response[i].Status = http.StatusCreated
+		case repository.BatchOperationUpdate:
+			response[i].Status = http.StatusOK
+		case repository.BatchOperationDelete:
+			response[i].Status = http.StatusNoContent
+		}
+	}
+
+	if len(failed) != 0 {
+		c.Set("error", errors.Join(failed...))
+	}
+
+	return c.JSON(http.StatusOK, map[string][]batchResultBody{
+		"results": response,
+	})
+}
+
+func (h *Handler) batchOperation(context context.Context, body *batchOperationBody) (repository.BatchOperation, error) {
+	operation := repository.BatchOperation{
+		Type:    repository.BatchOperationType(body.Op),
+		Version: body.Version,
+	}
+
+	switch operation.Type {
+	case repository.BatchOperationCreate:
+		if body.Subscription == nil {
+			return repository.BatchOperation{}, &domain.FieldError{Field: "subscription", Err: domain.ErrInvalidValue}
+		}
+
+		subscription, err := h.subscriptionFromBody(context, body.Subscription)
+		if err != nil {
+			return repository.BatchOperation{}, nestedFieldError("subscription", err)
+		}
+
+		operation.Subscription = subscription
+
+		return operation, nil
+	case repository.BatchOperationUpdate, repository.BatchOperationDelete:
+		id, err := uuid.Parse(body.ID)
+		if err != nil {
+			return repository.BatchOperation{}, &domain.FieldError{Field: "id", Err: domain.ErrInvalidID}
+		}
+
+		if body.Version <= 0 {
+			return repository.BatchOperation{}, &domain.FieldError{Field: "version", Err: domain.ErrVersionRequired}
+		}
+
+		operation.ID = id
+	default:
+		return repository.BatchOperation{}, &domain.FieldError{Field: "op", Err: domain.ErrInvalidBatch}
+	}
+
+	if operation.Type == repository.BatchOperationUpdate {
+		parameters, err := h.updateParameters(body.Patch)
+		if err != nil {
+			return repository.BatchOperation{}, nestedFieldError("patch", err)
+		}
+
+		operation.Parameters = parameters
+	}
+
+	return operation, nil
+}
+
+// nestedFieldError names the fields of err relative to the field parent.
+func nestedFieldError(parent string, err error) error {
+	var validationErr *domain.ValidationError
+	if errors.As(err, &validationErr) {
+		violations := make([]*domain.FieldError, len(validationErr.Violations))
+		for i, violation := range validationErr.Violations {
+			violations[i] = &domain.FieldError{Field: parent + "." + violation.Field, Err: violation.Err}
+		}
+
+		return &domain.ValidationError{Violations: violations}
+	}
+
+	var fieldErr *domain.FieldError
+	if errors.As(err, &fieldErr) {
+		return &domain.FieldError{Field: parent + "." + fieldErr.Field, Err: fieldErr.Err}
+	}
+
+	return &domain.FieldError{Field: parent, Err: err}
+}
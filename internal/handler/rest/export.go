@@ -0,0 +1,281 @@
+package rest
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mirrorblade/subscriptions/internal/domain"
+	"github.com/mirrorblade/subscriptions/internal/repository"
+)
+
+const (
+	mediaTypeCSV      = "text/csv"
+	mediaTypeNDJSON   = "application/x-ndjson"
+	mediaTypeCalendar = "text/calendar"
+)
+
+// exportMediaType returns the export media type the Accept header prefers,
+// or an empty string if it prefers JSON or names no export media type.
+func exportMediaType(accept string) string {
+	best, bestQuality := "", 0.0
+
+	for _, value := range strings.Split(accept, ",") {
+		mediaType, parameters, err := mime.ParseMediaType(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := parameters["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+
+		switch mediaType {
+		case mediaTypeCSV, mediaTypeNDJSON, mediaTypeCalendar:
+		case echo.MIMEApplicationJSON:
+			mediaType = ""
+		default:
+			continue
+		}
+
+		if quality > bestQuality {
+			best, bestQuality = mediaType, quality
+		}
+	}
+
+	return best
+}
+
+// exportSubscriptions streams the subscriptions matching parameters in the
+// format of mediaType. Once the first row is written the status can not be
+// changed anymore, so later errors are only logged.
+func (h *Handler) exportSubscriptions(c echo.Context, parameters repository.ListParameters, mediaType string) error {
+	response := c.Response()
+	exporter := newExporter(mediaType, response)
+
+	begin := func() error {
+		response.Header().Set(echo.HeaderContentType, mediaType+"; charset=utf-8")
+		response.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", exportFileName(mediaType)))
+		response.WriteHeader(http.StatusOK)
+
+		return exporter.begin()
+	}
+
+	err := h.service.Subscriptions.Export(c.Request().Context(), parameters, func(subscription domain.Subscription) error {
+		if !response.Committed {
+			if err := begin(); err != nil {
+				return err
+			}
+		}
+
+		return exporter.write(subscription)
+	})
+	if err == nil && !response.Committed {
+		err = begin()
+	}
+	if err == nil {
+		err = exporter.end()
+	}
+
+	if err != nil {
+		if response.Committed {
+			c.Set("error", err)
+
+			return nil
+		}
+
+		return err
+	}
+
+	response.Flush()
+
+	return nil
+}
+
+// exporter writes subscriptions one by one in an export format.
+type exporter interface {
+	begin() error
+	write(subscription domain.Subscription) error
+	end() error
+}
+
+func newExporter(mediaType string, w io.Writer) exporter {
+	switch mediaType {
+	case mediaTypeCSV:
+		return &csvExporter{writer: csv.NewWriter(w)}
+	case mediaTypeCalendar:
+		return &calendarExporter{writer: bufio.NewWriter(w), stamp: time.Now().UTC()}
+	default:
+		writer := bufio.NewWriter(w)
+
+		return &ndjsonExporter{writer: writer, encoder: json.NewEncoder(writer)}
+	}
+}
+
+func exportFileName(mediaType string) string {
+	switch mediaType {
+	case mediaTypeCSV:
+		return "subscriptions.csv"
+	case mediaTypeCalendar:
+		return "subscriptions.ics"
+	default:
+		return "subscriptions.ndjson"
+	}
+}
+
+// csvExporter writes the columns accepted by the import, so that an export
+// can be imported back.
+type csvExporter struct {
+	writer *csv.Writer
+}
+
+func (e *csvExporter) begin() error {
+	return e.writer.Write([]string{"id", "service_name", "price", "currency", "billing_period", "user_id", "start_date", "end_date"})
+}
+
+func (e *csvExporter) write(subscription domain.Subscription) error {
+	endDate := ""
+	if subscription.EndDate != nil {
+		endDate = subscription.EndDate.Format("01-2006")
+	}
+
+	return e.writer.Write([]string{
+		subscription.ID.String(),
+		subscription.ServiceName,
+		strconv.FormatInt(subscription.Price, 10),
+		string(subscription.Currency),
+		string(subscription.BillingPeriod),
+		subscription.UserID.String(),
+		subscription.StartDate.Format("01-2006"),
+		endDate,
+	})
+}
+
+func (e *csvExporter) end() error {
+	e.writer.Flush()
+
+	return e.writer.Error()
+}
+
+type ndjsonExporter struct {
+	writer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+func (e *ndjsonExporter) begin() error {
+	return nil
+}
+
+func (e *ndjsonExporter) write(subscription domain.Subscription) error {
+	return e.encoder.Encode(subscription)
+}
+
+func (e *ndjsonExporter) end() error {
+	return e.writer.Flush()
+}
+
+// calendarExporter writes an iCalendar (RFC 5545) with a recurring all-day
+// event per subscription that occurs on each of its billing dates.
+type calendarExporter struct {
+	writer *bufio.Writer
+	stamp  time.Time
+}
+
+func (e *calendarExporter) begin() error {
+	return e.lines(
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//mirrorblade//subscriptions//EN",
+		"CALSCALE:GREGORIAN",
+	)
+}
+
+func (e *calendarExporter) write(subscription domain.Subscription) error {
+	lines := []string{
+		"BEGIN:VEVENT",
+		"UID:" + subscription.ID.String() + "@subscriptions",
+		"DTSTAMP:" + e.stamp.Format("20060102T150405Z"),
+		"DTSTART;VALUE=DATE:" + subscription.StartDate.Format("20060102"),
+		"SUMMARY:" + calendarText(fmt.Sprintf("%s: %d %s", subscription.ServiceName, subscription.Price, subscription.Currency)),
+		"DESCRIPTION:" + calendarText(fmt.Sprintf("%s renewal of %s", subscription.BillingPeriod, subscription.ServiceName)),
+		"TRANSP:TRANSPARENT",
+	}
+
+	rule := calendarRule(subscription.BillingPeriod)
+
+	// Subscriptions are charged until the end of their last month.
+	if subscription.EndDate != nil {
+		rule += ";UNTIL=" + subscription.EndDate.AddDate(0, 1, -1).Format("20060102")
+	}
+
+	lines = append(lines, "RRULE:"+rule, "END:VEVENT")
+
+	return e.lines(lines...)
+}
+
+func (e *calendarExporter) end() error {
+	if err := e.lines("END:VCALENDAR"); err != nil {
+		return err
+	}
+
+	return e.writer.Flush()
+}
+
+// lines writes content lines folded to 75 octets and terminated by CRLF.
+func (e *calendarExporter) lines(lines ...string) error {
+	for _, line := range lines {
+		// Continuation lines start with a space, which counts to their size.
+		for size := 75; len(line) > size; size = 74 {
+			cut := size
+			for !isRuneStart(line[cut]) {
+				cut--
+			}
+
+			if _, err := e.writer.WriteString(line[:cut] + "\r\n "); err != nil {
+				return err
+			}
+
+			line = line[cut:]
+		}
+
+		if _, err := e.writer.WriteString(line + "\r\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+var calendarTextEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func calendarText(text string) string {
+	return calendarTextEscaper.Replace(text)
+}
+
+func calendarRule(period domain.BillingPeriod) string {
+	switch period {
+	case domain.BillingPeriodWeekly:
+		return "FREQ=WEEKLY"
+	case domain.BillingPeriodQuarterly:
+		return "FREQ=MONTHLY;INTERVAL=3"
+	case domain.BillingPeriodYearly:
+		return "FREQ=YEARLY"
+	default:
+		return "FREQ=MONTHLY"
+	}
+}
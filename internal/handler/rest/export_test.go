@@ -0,0 +1,90 @@
+package rest
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestExportMediaType(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"", ""},
+		{"*/*", ""},
+		{"application/json", ""},
+		{"text/csv", mediaTypeCSV},
+		{"TEXT/CSV; charset=utf-8", mediaTypeCSV},
+		{"application/x-ndjson", mediaTypeNDJSON},
+		{"text/calendar", mediaTypeCalendar},
+		{"text/csv;q=0.5, application/x-ndjson;q=0.8", mediaTypeNDJSON},
+		{"text/csv;q=0.9, application/json", ""},
+		{"application/json;q=0.5, text/calendar", mediaTypeCalendar},
+		{"text/csv, application/x-ndjson", mediaTypeCSV},
+		{"text/csv;q=0", ""},
+		{"text/csv;q=0, application/x-ndjson;q=0.1", mediaTypeNDJSON},
+		{"text/csv;q=high, text/calendar;q=0.2", mediaTypeCalendar},
+		{"text/html, text/*;q=0.9", ""},
+		{"text/csv;;, application/x-ndjson", mediaTypeNDJSON},
+	}
+
+	for _, tt := range tests {
+		if got := exportMediaType(tt.accept); got != tt.want {
+			t.Errorf("%q: media type = %q, want %q", tt.accept, got, tt.want)
+		}
+	}
+}
+
+func TestCalendarExporterLines(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{"short", "BEGIN:VCALENDAR", "BEGIN:VCALENDAR\r\n"},
+		{"75 octets", strings.Repeat("a", 75), strings.Repeat("a", 75) + "\r\n"},
+		{"76 octets", strings.Repeat("a", 76), strings.Repeat("a", 75) + "\r\n a\r\n"},
+		{"several folds", strings.Repeat("a", 75+74+1), strings.Repeat("a", 75) + "\r\n " + strings.Repeat("a", 74) + "\r\n a\r\n"},
+		{"two-byte rune across the limit", strings.Repeat("a", 74) + "жж", strings.Repeat("a", 74) + "\r\n жж\r\n"},
+		{"three-byte rune across the limit", strings.Repeat("a", 73) + "€€", strings.Repeat("a", 73) + "\r\n €€\r\n"},
+		{"four-byte rune across the limit", strings.Repeat("a", 72) + "🎵🎵", strings.Repeat("a", 72) + "\r\n 🎵🎵\r\n"},
+		{"rune ending at the limit", strings.Repeat("a", 73) + "жa", strings.Repeat("a", 73) + "ж\r\n a\r\n"},
+		{"only multibyte runes", strings.Repeat("ж", 100), ""},
+	}
+
+	for _, tt := range tests {
+		var buffer bytes.Buffer
+
+		exporter := &calendarExporter{writer: bufio.NewWriter(&buffer)}
+		if err := exporter.lines(tt.line); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		if err := exporter.writer.Flush(); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		got := buffer.String()
+
+		if tt.want != "" && got != tt.want {
+			t.Errorf("%s: lines = %q, want %q", tt.name, got, tt.want)
+		}
+
+		for _, line := range strings.Split(strings.TrimSuffix(got, "\r\n"), "\r\n") {
+			if len(line) > 75 {
+				t.Errorf("%s: line %q has %d octets", tt.name, line, len(line))
+			}
+
+			if !utf8.ValidString(line) {
+				t.Errorf("%s: line %q splits a rune", tt.name, line)
+			}
+		}
+
+		if unfolded := strings.ReplaceAll(strings.TrimSuffix(got, "\r\n"), "\r\n ", ""); unfolded != tt.line {
+			t.Errorf("%s: unfolded = %q, want %q", tt.name, unfolded, tt.line)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package rest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mirrorblade/subscriptions/internal/domain"
+)
+
+const headerIdempotencyKey = "Idempotency-Key"
+
+// maxIdempotentBodySize bounds the bodies of requests with an idempotency
+// key, which are read into memory to be hashed. It fits a batch of the
+// largest size.
+const maxIdempotentBodySize = 2 << 20
+
+var idempotentHeaders = []string{echo.HeaderContentType, echo.HeaderLocation, "ETag"}
+
+type responseRecorder struct {
+	http.ResponseWriter
+
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+
+	return r.ResponseWriter.Write(data)
+}
+
+// idempotent replays the stored response of a request whose Idempotency-Key
+// header was already seen with the same body, and stores the response of a
+// request seen for the first time. Requests without the header pass through.
+func (h *Handler) idempotent(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		key := c.Request().Header.Get(headerIdempotencyKey)
+		if key == "" {
+			return next(c)
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Response(), c.Request().Body, maxIdempotentBodySize))
+		if err != nil {
+			var maxBytesError *http.MaxBytesError
+			if errors.As(err, &maxBytesError) {
+				return domain.ErrBodyTooLarge
+			}
+
+			return fmt.Errorf("%w: %w", domain.ErrInvalidBody, err)
+		}
+		c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.New()
+		hash.Write([]byte(c.Request().Method + " " + c.Request().URL.Path + "\n"))
+		hash.Write(body)
+		requestHash := hex.EncodeToString(hash.Sum(nil))
+
+		response, err := h.service.Idempotency.Begin(c.Request().Context(), key, requestHash)
+		if err != nil {
+			return err
+		}
+
+		if response != nil {
+			for name, values := range response.Headers {
+				for _, value := range values {
+					c.Response().Header().Add(name, value)
+				}
+			}
+			c.Response().Header().Set("Idempotent-Replayed", "true")
+
+			c.Response().WriteHeader(response.Status)
+			_, err := c.Response().Write(response.Body)
+
+			return err
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+		c.Response().Writer = recorder
+
+		// Errors are turned into responses here, so that client errors are
+		// stored and replayed like any other response.
+		err = next(c)
+		if err != nil {
+			c.Error(err)
+		}
+
+		if c.Response().Status >= http.StatusInternalServerError {
+			if err := h.service.Idempotency.Release(c.Request().Context(), key); err != nil {
+				c.Set("error", err)
+			}
+
+			return err
+		}
+
+		headers := http.Header{}
+		for _, name := range idempotentHeaders {
+			if value := c.Response().Header().Get(name); value != "" {
+				headers.Set(name, value)
+			}
+		}
+
+		stored := domain.IdempotentResponse{
+			Status:  c.Response().Status,
+			Headers: headers,
+			Body:    recorder.body.Bytes(),
+		}
+
+		if err := h.service.Idempotency.Complete(c.Request().Context(), key, stored); err != nil {
+			c.Set("error", err)
+		}
+
+		return err
+	}
+}
@@ -0,0 +1,36 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mirrorblade/subscriptions/internal/importer"
+)
+
+func (h *Handler) importSubscriptions(c echo.Context) error {
+	format := importer.Format(c.QueryParam("format"))
+	if format == "" {
+		format = importer.FormatFromMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	}
+
+	if !format.Valid() {
+		return importer.ErrUnsupportedFormat
+	}
+
+	dryRun, err := boolQueryParam(c, "dry_run")
+	if err != nil {
+		return err
+	}
+
+	report, err := h.service.Import.Import(c.Request().Context(), c.Request().Body, format, dryRun != nil && *dryRun)
+	if err != nil {
+		return err
+	}
+
+	if len(report.Errors) != 0 {
+		c.Set("error", errors.New("some rows were not imported"))
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
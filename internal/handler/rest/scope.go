@@ -0,0 +1,24 @@
+package rest
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/mirrorblade/subscriptions/internal/domain"
+)
+
+// scope only lets through requests whose principal has scope.
+func scope(scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal, ok := domain.PrincipalFromContext(c.Request().Context())
+			if !ok {
+				return domain.ErrUnauthenticated
+			}
+
+			if !principal.HasScope(scope) {
+				return domain.ErrInsufficientScope
+			}
+
+			return next(c)
+		}
+	}
+}
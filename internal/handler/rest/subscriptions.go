@@ -1,319 +1,625 @@
 package rest
 
 import (
-	"errors"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/mirrorblade/subscriptions/internal/domain"
 	"github.com/mirrorblade/subscriptions/internal/repository"
+	"github.com/mirrorblade/subscriptions/internal/validation"
 )
 
 type createSubscriptionBody struct {
-	ServiceName string `json:"service_name"`
-	Price       int64  `json:"price"`
-	UserID      string `json:"user_id"`
-	StartDate   string `json:"start_date"`
-	EndDate     string `json:"end_date,omitempty"`
+	ServiceName   string `json:"service_name"`
+	Price         int64  `json:"price"`
+	Currency      string `json:"currency,omitempty"`
+	BillingPeriod string `json:"billing_period,omitempty"`
+	UserID        string `json:"user_id"`
+	StartDate     string `json:"start_date"`
+	EndDate       string `json:"end_date,omitempty"`
 }
 
 func (h *Handler) initSubscriptions(g *echo.Group) {
+	read, write, admin := scope(domain.ScopeRead), scope(domain.ScopeWrite), scope(domain.ScopeAdmin)
+
 	group := g.Group("/subscriptions")
-	group.GET("/:id", h.getSubscription)
-	group.GET("/", h.getSubscriptions)
-	group.GET("/price", h.getSubscriptionsSum)
-	group.POST("/", h.createSubscription)
-	group.PATCH("/:id", h.updateSubscription)
-	group.DELETE("/:id", h.deleteSubscription)
+	group.GET("/:id", h.getSubscription, read)
+	group.GET("/", h.getSubscriptions, read)
+	group.GET("/search", h.searchSubscriptions, read)
+	group.GET("/price", h.getSubscriptionsSum, read)
+	group.POST("/", h.createSubscription, write, h.idempotent)
+	group.POST("/batch", h.batchSubscriptions, write, h.idempotent)
+	group.POST("/import", h.importSubscriptions, admin)
+	group.PATCH("/:id", h.updateSubscription, write)
+	group.DELETE("/:id", h.deleteSubscription, write)
+	group.POST("/:id/restore", h.restoreSubscription, write)
+	group.GET("/:id/history", h.getSubscriptionHistory, read)
 }
 
 func (h *Handler) getSubscription(c echo.Context) error {
-	id, err := uuid.Parse(c.Param("id"))
+	id, err := idParam(c)
 	if err != nil {
-		c.Set("error", err)
-
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"message": "bad request",
-		})
+		return err
 	}
 
-	subscription, err := h.service.Subscriptions.GetByID(c.Request().Context(), id)
+	includeDeleted, err := boolQueryParam(c, "include_deleted")
 	if err != nil {
-		c.Set("error", err)
-
-		if errors.Is(err, domain.ErrSubscriptionNotFound) {
-			return c.JSON(http.StatusNotFound, map[string]string{
-				"message": "not found",
-			})
-		}
+		return err
+	}
 
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"message": "internal server error",
-		})
+	subscription, err := h.service.Subscriptions.GetByID(c.Request().Context(), id, includeDeleted != nil && *includeDeleted)
+	if err != nil {
+		return err
 	}
 
+	c.Response().Header().Set("ETag", etag(subscription.Version))
+
 	return c.JSON(http.StatusOK, subscription)
 }
 
 func (h *Handler) getSubscriptions(c echo.Context) error {
-	userID, err := uuid.Parse(c.QueryParam("user_id"))
+	userID, err := uuidQueryParam(c, "user_id")
 	if err != nil {
-		c.Set("error", err)
-
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"message": "bad request",
-		})
+		return err
 	}
 
-	subscriptions, err := h.service.Subscriptions.GetListByUserID(c.Request().Context(), userID)
+	parameters, err := h.listParameters(c)
 	if err != nil {
-		c.Set("error", err)
+		return err
+	}
 
-		if errors.Is(err, domain.ErrUserNotFound) {
-			return c.JSON(http.StatusNotFound, map[string]string{
-				"message": "not found",
-			})
-		}
+	parameters.Filter.UserID = &userID
+
+	c.Response().Header().Add(echo.HeaderVary, echo.HeaderAccept)
 
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"message": "internal server error",
-		})
+	if mediaType := exportMediaType(c.Request().Header.Get(echo.HeaderAccept)); mediaType != "" {
+		return h.exportSubscriptions(c, parameters, mediaType)
+	}
+
+	subscriptions, err := h.service.Subscriptions.GetList(c.Request().Context(), parameters)
+	if err != nil {
+		return err
 	}
 
 	return c.JSON(http.StatusOK, subscriptions)
 }
 
-func (h *Handler) getSubscriptionsSum(c echo.Context) error {
-	userID, err := uuid.Parse(c.QueryParam("user_id"))
+func (h *Handler) searchSubscriptions(c echo.Context) error {
+	parameters, err := h.searchParameters(c)
 	if err != nil {
-		c.Set("error", err)
+		return err
+	}
 
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"message": "bad request",
-		})
+	result, err := h.service.Subscriptions.Search(c.Request().Context(), parameters)
+	if err != nil {
+		return err
 	}
 
-	var serviceName *string
-	dirtyServiceName := c.QueryParam("service_name")
-	if dirtyServiceName != "" {
-		sanitizedServiceName := h.sanitizer.Sanitize(dirtyServiceName)
-		serviceName = &sanitizedServiceName
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) listParameters(c echo.Context) (repository.ListParameters, error) {
+	var (
+		parameters repository.ListParameters
+		err        error
+	)
+
+	if parameters.Filter, err = h.listFilter(c); err != nil {
+		return repository.ListParameters{}, err
 	}
 
-	var fromDate *time.Time
-	dirtyFromDate := c.QueryParam("from_date")
-	if dirtyFromDate != "" {
-		date, err := time.Parse("01-2006", dirtyFromDate)
-		if err != nil {
-			c.Set("error", err)
+	if parameters.SortBy, parameters.Descending, err = sortQueryParams(c); err != nil {
+		return repository.ListParameters{}, err
+	}
 
-			return c.JSON(http.StatusBadRequest, map[string]string{
-				"message": "bad request",
-			})
-		}
-		fromDate = &date
+	limit, err := intQueryParam(c, "limit")
+	if err != nil {
+		return repository.ListParameters{}, err
+	}
+	if limit != nil {
+		parameters.Limit = int(*limit)
 	}
 
-	var toDate *time.Time
-	dirtyToDate := c.QueryParam("to_date")
-	if dirtyToDate != "" {
-		date, err := time.Parse("01-2006", dirtyToDate)
+	if token := c.QueryParam("cursor"); token != "" {
+		cursor, err := repository.DecodeCursor(token)
 		if err != nil {
-			c.Set("error", err)
+			return repository.ListParameters{}, &domain.FieldError{Field: "cursor", Err: err}
+		}
+		parameters.After = &cursor
+	}
+
+	return parameters, nil
+}
+
+func (h *Handler) searchParameters(c echo.Context) (repository.SearchParameters, error) {
+	var (
+		parameters repository.SearchParameters
+		err        error
+	)
+
+	if parameters.Filter, err = h.listFilter(c); err != nil {
+		return repository.SearchParameters{}, err
+	}
 
-			return c.JSON(http.StatusBadRequest, map[string]string{
-				"message": "bad request",
-			})
+	if c.QueryParam("user_id") != "" {
+		userID, err := uuidQueryParam(c, "user_id")
+		if err != nil {
+			return repository.SearchParameters{}, err
 		}
-		toDate = &date
+		parameters.Filter.UserID = &userID
 	}
 
-	parameters := repository.GetSumParameters{
-		ServiceName: serviceName,
-		FromDate:    fromDate,
-		ToDate:      toDate,
+	if parameters.Filter.Active, err = boolQueryParam(c, "active"); err != nil {
+		return repository.SearchParameters{}, err
 	}
 
-	price, err := h.service.Subscriptions.GetPriceSumByUserID(c.Request().Context(), userID, parameters)
+	if parameters.Filter.ActiveFrom, err = dateQueryParam(c, "from_date"); err != nil {
+		return repository.SearchParameters{}, err
+	}
+	if parameters.Filter.ActiveTo, err = dateQueryParam(c, "to_date"); err != nil {
+		return repository.SearchParameters{}, err
+	}
+
+	if parameters.SortBy, parameters.Descending, err = sortQueryParams(c); err != nil {
+		return repository.SearchParameters{}, err
+	}
+
+	limit, err := intQueryParam(c, "limit")
 	if err != nil {
-		c.Set("error", err)
+		return repository.SearchParameters{}, err
+	}
+	if limit != nil {
+		parameters.Limit = int(*limit)
+	}
 
-		if errors.Is(err, domain.ErrUserNotFound) {
-			return c.JSON(http.StatusNotFound, map[string]string{
-				"message": "not found",
-			})
-		}
+	offset, err := intQueryParam(c, "offset")
+	if err != nil {
+		return repository.SearchParameters{}, err
+	}
+	if offset != nil {
+		parameters.Offset = int(*offset)
+	}
+
+	return parameters, nil
+}
+
+func (h *Handler) listFilter(c echo.Context) (repository.ListFilter, error) {
+	var (
+		filter repository.ListFilter
+		err    error
+	)
+
+	if serviceName := c.QueryParam("service_name"); serviceName != "" {
+		sanitizedServiceName := h.sanitizer.Sanitize(serviceName)
+		filter.ServiceName = &sanitizedServiceName
+	}
+
+	if serviceNamePrefix := c.QueryParam("service_name_prefix"); serviceNamePrefix != "" {
+		sanitizedServiceNamePrefix := h.sanitizer.Sanitize(serviceNamePrefix)
+		filter.ServiceNamePrefix = &sanitizedServiceNamePrefix
+	}
 
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"message": "internal server error",
-		})
+	includeDeleted, err := boolQueryParam(c, "include_deleted")
+	if err != nil {
+		return repository.ListFilter{}, err
+	}
+	filter.IncludeDeleted = includeDeleted != nil && *includeDeleted
+
+	if filter.MinPrice, err = intQueryParam(c, "min_price"); err != nil {
+		return repository.ListFilter{}, err
+	}
+	if filter.MaxPrice, err = intQueryParam(c, "max_price"); err != nil {
+		return repository.ListFilter{}, err
+	}
+	if filter.ActiveOn, err = dateQueryParam(c, "active_on"); err != nil {
+		return repository.ListFilter{}, err
+	}
+	if filter.StartDateFrom, err = dateQueryParam(c, "start_date_from"); err != nil {
+		return repository.ListFilter{}, err
+	}
+	if filter.StartDateTo, err = dateQueryParam(c, "start_date_to"); err != nil {
+		return repository.ListFilter{}, err
+	}
+	if filter.EndDateFrom, err = dateQueryParam(c, "end_date_from"); err != nil {
+		return repository.ListFilter{}, err
+	}
+	if filter.EndDateTo, err = dateQueryParam(c, "end_date_to"); err != nil {
+		return repository.ListFilter{}, err
 	}
 
-	return c.JSON(http.StatusOK, price)
+	return filter, nil
 }
 
-func (h *Handler) createSubscription(c echo.Context) error {
-	body := new(createSubscriptionBody)
-	if err := c.Bind(body); err != nil {
-		c.Set("error", err)
+func sortQueryParams(c echo.Context) (repository.SortField, bool, error) {
+	sortBy := repository.SortField(c.QueryParam("sort_by"))
 
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"message": "bad request",
-		})
+	switch c.QueryParam("order") {
+	case "", "asc":
+		return sortBy, false, nil
+	case "desc":
+		return sortBy, true, nil
+	default:
+		return "", false, &domain.FieldError{Field: "order", Err: domain.ErrInvalidSort}
 	}
+}
+
+func idParam(c echo.Context) (uuid.UUID, error) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return uuid.UUID{}, &domain.FieldError{Field: "id", Err: domain.ErrInvalidID}
+	}
+
+	return id, nil
+}
 
-	userID, err := uuid.Parse(body.UserID)
+func uuidQueryParam(c echo.Context, name string) (uuid.UUID, error) {
+	id, err := uuid.Parse(c.QueryParam(name))
 	if err != nil {
-		c.Set("error", err)
+		return uuid.UUID{}, &domain.FieldError{Field: name, Err: domain.ErrInvalidID}
+	}
+
+	return id, nil
+}
 
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"message": "bad request",
-		})
+func intQueryParam(c echo.Context, name string) (*int64, error) {
+	dirtyValue := c.QueryParam(name)
+	if dirtyValue == "" {
+		return nil, nil
 	}
 
-	startDate, err := time.Parse("01-2006", body.StartDate)
+	value, err := strconv.ParseInt(dirtyValue, 10, 64)
 	if err != nil {
-		c.Set("error", err)
+		return nil, &domain.FieldError{Field: name, Err: domain.ErrInvalidValue}
+	}
+
+	return &value, nil
+}
 
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"message": "bad request",
-		})
+func boolQueryParam(c echo.Context, name string) (*bool, error) {
+	dirtyValue := c.QueryParam(name)
+	if dirtyValue == "" {
+		return nil, nil
 	}
 
-	var endDate *time.Time
+	value, err := strconv.ParseBool(dirtyValue)
+	if err != nil {
+		return nil, &domain.FieldError{Field: name, Err: domain.ErrInvalidValue}
+	}
 
-	if body.EndDate != "" {
-		date, err := time.Parse("01-2006", body.EndDate)
-		if err != nil {
-			c.Set("error", err)
+	return &value, nil
+}
 
-			return c.JSON(http.StatusBadRequest, map[string]string{
-				"message": "bad request",
-			})
-		}
+func dateQueryParam(c echo.Context, name string) (*time.Time, error) {
+	dirtyValue := c.QueryParam(name)
+	if dirtyValue == "" {
+		return nil, nil
+	}
+
+	date, err := time.Parse("01-2006", dirtyValue)
+	if err != nil {
+		return nil, &domain.FieldError{Field: name, Err: domain.ErrInvalidDate}
+	}
+
+	return &date, nil
+}
 
-		endDate = &date
+func (h *Handler) getSubscriptionsSum(c echo.Context) error {
+	userID, err := uuidQueryParam(c, "user_id")
+	if err != nil {
+		return err
 	}
 
-	subscription := domain.Subscription{
-		ServiceName: h.sanitizer.Sanitize(body.ServiceName),
-		Price:       body.Price,
-		UserID:      userID,
-		StartDate:   startDate,
-		EndDate:     endDate,
+	var serviceName *string
+	dirtyServiceName := c.QueryParam("service_name")
+	if dirtyServiceName != "" {
+		sanitizedServiceName := h.sanitizer.Sanitize(dirtyServiceName)
+		serviceName = &sanitizedServiceName
 	}
 
-	if err := h.service.Subscriptions.Create(c.Request().Context(), subscription); err != nil {
-		c.Set("error", err)
+	fromDate, err := dateQueryParam(c, "from_date")
+	if err != nil {
+		return err
+	}
 
-		if errors.Is(err, domain.ErrInvalidPrice) {
-			return c.JSON(http.StatusBadRequest, map[string]string{
-				"message": "bad request",
-			})
-		}
+	toDate, err := dateQueryParam(c, "to_date")
+	if err != nil {
+		return err
+	}
 
-		if errors.Is(err, domain.ErrInvalidDate) {
-			return c.JSON(http.StatusBadRequest, map[string]string{
-				"message": "bad request",
-			})
+	parameters := repository.GetSumParameters{
+		ServiceName: serviceName,
+		FromDate:    fromDate,
+		ToDate:      toDate,
+		Period:      domain.BillingPeriod(c.QueryParam("period")),
+		Currency:    domain.Currency(strings.ToUpper(c.QueryParam("currency"))),
+	}
+
+	sum, err := h.service.Subscriptions.GetPriceSumByUserID(c.Request().Context(), userID, parameters)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, sum)
+}
+
+func (h *Handler) createSubscription(c echo.Context) error {
+	body := new(createSubscriptionBody)
+	if err := c.Bind(body); err != nil {
+		return fmt.Errorf("%w: %w", domain.ErrInvalidBody, err)
+	}
+
+	subscription, err := h.subscriptionFromBody(c.Request().Context(), body)
+	if err != nil {
+		return err
+	}
+
+	subscription, err = h.service.Subscriptions.Create(c.Request().Context(), subscription)
+	if err != nil {
+		return err
+	}
+
+	c.Response().Header().Set(echo.HeaderLocation, strings.TrimSuffix(c.Request().URL.Path, "/")+"/"+subscription.ID.String())
+	c.Response().Header().Set("ETag", etag(subscription.Version))
+
+	return c.JSON(http.StatusCreated, subscription)
+}
+
+// subscriptionFromBody converts body into a subscription. Subscriptions
+// without a user belong to the authenticated user, so callers that are not
+// users must name one.
+func (h *Handler) subscriptionFromBody(context context.Context, body *createSubscriptionBody) (domain.Subscription, error) {
+	if principal, ok := domain.PrincipalFromContext(context); ok && body.UserID == "" {
+		if principal.UserID == uuid.Nil {
+			return domain.Subscription{}, &domain.FieldError{Field: "user_id", Err: domain.ErrUserRequired}
 		}
 
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"message": "internal server error",
-		})
+		body.UserID = principal.UserID.String()
 	}
 
-	return c.JSON(http.StatusCreated, map[string]string{
-		"message": "subscription was succesfully created",
+	return validation.ParseSubscription(validation.SubscriptionInput{
+		ServiceName:   h.sanitizer.Sanitize(body.ServiceName),
+		Price:         body.Price,
+		Currency:      body.Currency,
+		BillingPeriod: body.BillingPeriod,
+		UserID:        body.UserID,
+		StartDate:     body.StartDate,
+		EndDate:       body.EndDate,
 	})
 }
 
 func (h *Handler) updateSubscription(c echo.Context) error {
-	id, err := uuid.Parse(c.Param("id"))
+	id, err := idParam(c)
 	if err != nil {
-		c.Set("error", err)
+		return err
+	}
 
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"message": "bad request",
-		})
+	versions, err := ifMatchVersions(c.Request().Header.Get("If-Match"))
+	if err != nil {
+		return err
 	}
 
-	var price *int64
-	dirtyPrice := c.QueryParam("price")
-	if dirtyPrice != "" {
-		clearPrice, err := strconv.ParseInt(dirtyPrice, 10, 64)
-		if err != nil {
-			c.Set("error", err)
+	patch := map[string]json.RawMessage{}
+	if err := json.NewDecoder(c.Request().Body).Decode(&patch); err != nil {
+		return fmt.Errorf("%w: %w", domain.ErrInvalidBody, err)
+	}
 
-			return c.JSON(http.StatusBadRequest, map[string]string{
-				"message": "bad request",
-			})
-		}
-		price = &clearPrice
+	parameters, err := h.updateParameters(patch)
+	if err != nil {
+		return err
 	}
 
-	var endDate *time.Time
-	dirtyEndDate := c.QueryParam("end_date")
-	if dirtyEndDate != "" {
-		date, err := time.Parse("01-2006", dirtyEndDate)
-		if err != nil {
-			c.Set("error", err)
+	subscription, err := h.service.Subscriptions.UpdateByID(c.Request().Context(), id, versions, parameters)
+	if err != nil {
+		return err
+	}
+
+	c.Response().Header().Set("ETag", etag(subscription.Version))
 
-			return c.JSON(http.StatusBadRequest, map[string]string{
-				"message": "bad request",
-			})
+	return c.JSON(http.StatusOK, subscription)
+}
+
+// updateParameters converts a JSON Merge Patch (RFC 7396) document, in which
+// only end_date may be removed with an explicit null, into update parameters,
+// and reports the violations of all its fields together.
+func (h *Handler) updateParameters(patch map[string]json.RawMessage) (repository.UpdateParameters, error) {
+	var parameters repository.UpdateParameters
+
+	v := new(validation.Validator)
+
+	for _, field := range slices.Sorted(maps.Keys(patch)) {
+		value := patch[field]
+
+		if field == "end_date" && isJSONNull(value) {
+			parameters.ClearEndDate = true
+
+			continue
+		}
+
+		if isJSONNull(value) {
+			v.Add(field, fmt.Errorf("%w: field can not be removed", domain.ErrInvalidValue))
+
+			continue
+		}
+
+		switch field {
+		case "service_name":
+			var serviceName string
+			if err := json.Unmarshal(value, &serviceName); err != nil {
+				v.Add(field, domain.ErrInvalidServiceName)
+
+				continue
+			}
+
+			serviceName = h.sanitizer.Sanitize(serviceName)
+			v.ServiceName(field, serviceName)
+			parameters.ServiceName = &serviceName
+		case "price":
+			var price int64
+			if err := json.Unmarshal(value, &price); err != nil {
+				v.Add(field, domain.ErrInvalidPrice)
+
+				continue
+			}
+
+			v.Price(field, price)
+			parameters.Price = &price
+		case "currency":
+			var currency string
+			if err := json.Unmarshal(value, &currency); err != nil {
+				v.Add(field, domain.ErrInvalidCurrency)
+
+				continue
+			}
+
+			clearCurrency := domain.Currency(strings.ToUpper(currency))
+			v.Currency(field, clearCurrency)
+			parameters.Currency = &clearCurrency
+		case "billing_period":
+			var billingPeriod domain.BillingPeriod
+			if err := json.Unmarshal(value, &billingPeriod); err != nil {
+				v.Add(field, domain.ErrInvalidBillingPeriod)
+
+				continue
+			}
+
+			v.BillingPeriod(field, billingPeriod)
+			parameters.BillingPeriod = &billingPeriod
+		case "user_id":
+			var dirtyUserID string
+			if err := json.Unmarshal(value, &dirtyUserID); err != nil {
+				v.Add(field, domain.ErrInvalidID)
+
+				continue
+			}
+
+			userID := v.ID(field, dirtyUserID)
+			parameters.UserID = &userID
+		case "start_date", "end_date":
+			var dirtyDate string
+			if err := json.Unmarshal(value, &dirtyDate); err != nil {
+				v.Add(field, domain.ErrInvalidDate)
+
+				continue
+			}
+
+			date := v.Date(field, dirtyDate)
+
+			if field == "start_date" {
+				parameters.StartDate = &date
+			} else {
+				parameters.EndDate = &date
+			}
+		default:
+			v.Add(field, fmt.Errorf("%w: field can not be updated", domain.ErrInvalidValue))
 		}
-		endDate = &date
 	}
 
-	parameters := repository.UpdateParameters{
-		Price:   price,
-		EndDate: endDate,
+	if err := v.Err(); err != nil {
+		return repository.UpdateParameters{}, err
 	}
 
-	if err := h.service.Subscriptions.UpdateByID(c.Request().Context(), id, parameters); err != nil {
-		c.Set("error", err)
+	return parameters, nil
+}
+
+func isJSONNull(value json.RawMessage) bool {
+	return string(bytes.TrimSpace(value)) == "null"
+}
 
-		if errors.Is(err, domain.ErrSubscriptionNotFound) {
-			return c.JSON(http.StatusNotFound, map[string]string{
-				"message": "not found",
-			})
-		}
+func (h *Handler) deleteSubscription(c echo.Context) error {
+	id, err := idParam(c)
+	if err != nil {
+		return err
+	}
 
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"message": "internal server error",
-		})
+	versions, err := ifMatchVersions(c.Request().Header.Get("If-Match"))
+	if err != nil {
+		return err
+	}
+
+	if err := h.service.Subscriptions.DeleteByID(c.Request().Context(), id, versions); err != nil {
+		return err
 	}
 
 	return c.NoContent(http.StatusNoContent)
 }
 
-func (h *Handler) deleteSubscription(c echo.Context) error {
-	id, err := uuid.Parse(c.Param("id"))
+func (h *Handler) restoreSubscription(c echo.Context) error {
+	id, err := idParam(c)
+	if err != nil {
+		return err
+	}
+
+	subscription, err := h.service.Subscriptions.RestoreByID(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	c.Response().Header().Set("ETag", etag(subscription.Version))
+
+	return c.JSON(http.StatusOK, subscription)
+}
+
+func (h *Handler) getSubscriptionHistory(c echo.Context) error {
+	id, err := idParam(c)
+	if err != nil {
+		return err
+	}
+
+	records, err := h.service.Subscriptions.GetHistoryByID(c.Request().Context(), id)
 	if err != nil {
-		c.Set("error", err)
+		return err
+	}
+
+	return c.JSON(http.StatusOK, records)
+}
+
+func etag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// ifMatchVersions returns the subscription versions of the entity tags in an
+// If-Match header, or none when any version is accepted. If-Match uses the
+// strong comparison, so weak tags and tags that are not versions can never
+// match; if no tag can, the precondition fails.
+func ifMatchVersions(header string) ([]int64, error) {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return nil, &domain.FieldError{Field: "If-Match", Err: domain.ErrVersionRequired}
+	}
 
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"message": "bad request",
-		})
+	if header == "*" {
+		return nil, nil
 	}
 
-	if err := h.service.Subscriptions.DeleteByID(c.Request().Context(), id); err != nil {
-		c.Set("error", err)
+	var versions []int64
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
 
-		if errors.Is(err, domain.ErrSubscriptionNotFound) {
-			return c.JSON(http.StatusNotFound, map[string]string{
-				"message": "not found",
-			})
+		version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+		if err != nil || version <= 0 || etag(version) != tag {
+			continue
 		}
 
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"message": "internal server error",
-		})
+		versions = append(versions, version)
 	}
 
-	return c.NoContent(http.StatusNoContent)
+	if len(versions) == 0 {
+		return nil, &domain.FieldError{Field: "If-Match", Err: domain.ErrVersionMismatch}
+	}
+
+	return versions, nil
 }
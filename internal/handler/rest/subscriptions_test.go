@@ -0,0 +1,189 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/mirrorblade/subscriptions/internal/domain"
+	"github.com/mirrorblade/subscriptions/internal/repository"
+)
+
+func TestIfMatchVersions(t *testing.T) {
+	tests := []struct {
+		header   string
+		versions []int64
+		err      error
+	}{
+		{``, nil, domain.ErrVersionRequired},
+		{`  `, nil, domain.ErrVersionRequired},
+		{`*`, nil, nil},
+		{`"3"`, []int64{3}, nil},
+		{` "3" `, []int64{3}, nil},
+		{`"3", "5"`, []int64{3, 5}, nil},
+		{`"3","5"`, []int64{3, 5}, nil},
+		{`W/"3"`, nil, domain.ErrVersionMismatch},
+		{`W/"3", "5"`, []int64{5}, nil},
+		{`"abc", "5"`, []int64{5}, nil},
+		{`3`, nil, domain.ErrVersionMismatch},
+		{`'3'`, nil, domain.ErrVersionMismatch},
+		{`"+3"`, nil, domain.ErrVersionMismatch},
+		{`"03"`, nil, domain.ErrVersionMismatch},
+		{`"0"`, nil, domain.ErrVersionMismatch},
+		{`"-1"`, nil, domain.ErrVersionMismatch},
+	}
+
+	for _, tt := range tests {
+		versions, err := ifMatchVersions(tt.header)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%q: err = %v, want %v", tt.header, err, tt.err)
+		}
+
+		if !slices.Equal(versions, tt.versions) {
+			t.Errorf("%q: versions = %v, want %v", tt.header, versions, tt.versions)
+		}
+	}
+}
+
+func TestUpdateParameters(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name       string
+		patch      string
+		check      func(repository.UpdateParameters) bool
+		violations []domain.FieldError
+	}{
+		{
+			name:  "every field",
+			patch: `{"service_name":"Yandex Plus","price":400,"currency":"usd","billing_period":"yearly","user_id":"` + userID.String() + `","start_date":"07-2025","end_date":"12-2025"}`,
+			check: func(p repository.UpdateParameters) bool {
+				return *p.ServiceName == "Yandex Plus" && *p.Price == 400 && *p.Currency == "USD" &&
+					*p.BillingPeriod == domain.BillingPeriodYearly && *p.UserID == userID &&
+					p.StartDate.Equal(time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)) &&
+					p.EndDate.Equal(time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)) && !p.ClearEndDate
+			},
+		},
+		{
+			name:  "sanitized service name",
+			patch: `{"service_name":"<b>Netflix</b><script>alert(1)</script>"}`,
+			check: func(p repository.UpdateParameters) bool {
+				return *p.ServiceName == "<b>Netflix</b>" && p.Price == nil
+			},
+		},
+		{
+			name:  "removed end date",
+			patch: `{"end_date":null}`,
+			check: func(p repository.UpdateParameters) bool {
+				return p.ClearEndDate && p.EndDate == nil
+			},
+		},
+		{
+			name:  "removed required field",
+			patch: `{"price":null}`,
+			violations: []domain.FieldError{
+				{Field: "price", Err: domain.ErrInvalidValue},
+			},
+		},
+		{
+			name:  "every violation",
+			patch: `{"user_id":"me","price":-1,"id":"` + userID.String() + `","currency":"XXX","billing_period":"daily","start_date":"2025-07-01","service_name":" "}`,
+			violations: []domain.FieldError{
+				{Field: "billing_period", Err: domain.ErrInvalidBillingPeriod},
+				{Field: "currency", Err: domain.ErrInvalidCurrency},
+				{Field: "id", Err: domain.ErrInvalidValue},
+				{Field: "price", Err: domain.ErrInvalidPrice},
+				{Field: "service_name", Err: domain.ErrInvalidServiceName},
+				{Field: "start_date", Err: domain.ErrInvalidDate},
+				{Field: "user_id", Err: domain.ErrInvalidID},
+			},
+		},
+		{
+			name:  "wrong types",
+			patch: `{"price":"400","end_date":7}`,
+			violations: []domain.FieldError{
+				{Field: "end_date", Err: domain.ErrInvalidDate},
+				{Field: "price", Err: domain.ErrInvalidPrice},
+			},
+		},
+	}
+
+	h := New(nil, bluemonday.UGCPolicy())
+
+	for _, tt := range tests {
+		patch := map[string]json.RawMessage{}
+		if err := json.Unmarshal([]byte(tt.patch), &patch); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		parameters, err := h.updateParameters(patch)
+
+		if tt.violations == nil {
+			if err != nil {
+				t.Errorf("%s: err = %v", tt.name, err)
+			} else if !tt.check(parameters) {
+				t.Errorf("%s: parameters = %+v", tt.name, parameters)
+			}
+
+			continue
+		}
+
+		var validationError *domain.ValidationError
+		if !errors.As(err, &validationError) {
+			t.Errorf("%s: err = %v, want a validation error", tt.name, err)
+
+			continue
+		}
+
+		if len(validationError.Violations) != len(tt.violations) {
+			t.Errorf("%s: violations = %v, want %d", tt.name, validationError, len(tt.violations))
+
+			continue
+		}
+
+		for i, violation := range validationError.Violations {
+			if violation.Field != tt.violations[i].Field || !errors.Is(violation, tt.violations[i].Err) {
+				t.Errorf("%s: violation %d = %v, want %s: %v", tt.name, i, violation, tt.violations[i].Field, tt.violations[i].Err)
+			}
+		}
+	}
+}
+
+func TestCreateSubscriptionWithoutUser(t *testing.T) {
+	const subscription = `{"service_name":"Yandex Plus","price":400,"start_date":"07-2025"}`
+
+	tests := []struct {
+		name   string
+		body   string
+		handle func(*Handler, echo.Context) error
+		field  string
+	}{
+		{"single", subscription, (*Handler).createSubscription, "user_id"},
+		{"batch", `{"mode":"atomic","operations":[{"op":"create","subscription":` + subscription + `}]}`, (*Handler).batchSubscriptions, "operations[0].subscription.user_id"},
+	}
+
+	h := New(nil, bluemonday.UGCPolicy())
+	router := echo.New()
+	principal := domain.Principal{APIKeyID: uuid.New(), Scopes: []string{domain.ScopeRead, domain.ScopeWrite}}
+
+	for _, tt := range tests {
+		request := httptest.NewRequest(http.MethodPost, "/rest/subscriptions/", strings.NewReader(tt.body))
+		request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		request = request.WithContext(domain.WithPrincipal(request.Context(), principal))
+
+		err := tt.handle(h, router.NewContext(request, httptest.NewRecorder()))
+
+		var fieldErr *domain.FieldError
+		if !errors.As(err, &fieldErr) || fieldErr.Field != tt.field || !errors.Is(err, domain.ErrUserRequired) {
+			t.Errorf("%s: err = %v, want %s: %v", tt.name, err, tt.field, domain.ErrUserRequired)
+		}
+	}
+}
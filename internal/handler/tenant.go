@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/mirrorblade/subscriptions/internal/domain"
+)
+
+// tenant stores the tenant of the request in the request context. API keys
+// and tokens issued for a tenant bind the request to it, and other tokens to
+// the default tenant. Only admins may choose another tenant with the tenancy
+// header.
+func (h *Handler) tenant(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		principal, _ := domain.PrincipalFromContext(c.Request().Context())
+
+		header := h.config.Tenancy.Header
+		requested := c.Request().Header.Get(header)
+
+		tenantID := principal.TenantID
+		if tenantID == "" {
+			tenantID = h.config.Tenancy.Default
+		}
+
+		if requested != "" && requested != tenantID {
+			if !principal.Admin() {
+				return &domain.FieldError{Field: header, Err: domain.ErrForbidden}
+			}
+
+			tenantID = requested
+		}
+
+		if !domain.ValidTenantID(tenantID) {
+			return &domain.FieldError{Field: header, Err: domain.ErrInvalidTenant}
+		}
+
+		context := domain.WithTenant(c.Request().Context(), tenantID)
+
+		c.SetRequest(c.Request().WithContext(context))
+
+		return next(c)
+	}
+}
@@ -0,0 +1,2 @@
+// Package importer provides functionality for reading subscriptions from files
+package importer
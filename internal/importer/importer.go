@@ -0,0 +1,248 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+)
+
+func (f Format) Valid() bool {
+	switch f {
+	case FormatCSV, FormatNDJSON:
+		return true
+	}
+
+	return false
+}
+
+// FormatFromMediaType returns the format of a Content-Type or Accept value,
+// or an empty format if the media type is not supported.
+func FormatFromMediaType(mediaType string) Format {
+	mediaType, _, _ = mime.ParseMediaType(mediaType)
+
+	switch mediaType {
+	case "text/csv":
+		return FormatCSV
+	case "application/x-ndjson", "application/jsonl":
+		return FormatNDJSON
+	}
+
+	return ""
+}
+
+// maxLineSize limits the size of a single NDJSON line.
+const maxLineSize = 1 << 20
+
+var (
+	ErrUnsupportedFormat = errors.New("import format is not supported")
+	ErrInvalidFile       = errors.New("import file is not valid")
+)
+
+// Record is a raw subscription as it appears in the imported file. Values
+// are neither sanitized nor validated.
+type Record struct {
+	Row           int    `json:"-"`
+	ServiceName   string `json:"service_name"`
+	Price         int64  `json:"price"`
+	Currency      string `json:"currency"`
+	BillingPeriod string `json:"billing_period"`
+	UserID        string `json:"user_id"`
+	StartDate     string `json:"start_date"`
+	EndDate       string `json:"end_date"`
+}
+
+// RowError is returned for a row that can not be read. Reading can continue
+// with the next row.
+type RowError struct {
+	Row int
+	Err error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+type Reader interface {
+	// Read returns the next record, or io.EOF when there are no more records.
+	Read() (Record, error)
+}
+
+func NewReader(r io.Reader, format Format) (Reader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVReader(r)
+	case FormatNDJSON:
+		return newNDJSONReader(r), nil
+	}
+
+	return nil, ErrUnsupportedFormat
+}
+
+var (
+	csvColumns         = []string{"service_name", "price", "currency", "billing_period", "user_id", "start_date", "end_date"}
+	csvRequiredColumns = []string{"service_name", "price", "user_id", "start_date"}
+	// csvIgnoredColumns are written by the export but can not be imported.
+	csvIgnoredColumns = []string{"id"}
+)
+
+// csvReader reads records from CSV with a header row naming the columns.
+type csvReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: csv header is missing", ErrInvalidFile)
+		}
+
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+
+	columns := make(map[string]int, len(header))
+
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+
+		if slices.Contains(csvIgnoredColumns, column) {
+			continue
+		}
+
+		if !slices.Contains(csvColumns, column) {
+			return nil, fmt.Errorf("%w: csv column %q is not supported", ErrInvalidFile, column)
+		}
+
+		if _, ok := columns[column]; ok {
+			return nil, fmt.Errorf("%w: csv column %q is duplicated", ErrInvalidFile, column)
+		}
+
+		columns[column] = i
+	}
+
+	for _, column := range csvRequiredColumns {
+		if _, ok := columns[column]; !ok {
+			return nil, fmt.Errorf("%w: csv column %q is required", ErrInvalidFile, column)
+		}
+	}
+
+	return &csvReader{
+		reader:  reader,
+		columns: columns,
+	}, nil
+}
+
+func (r *csvReader) Read() (Record, error) {
+	fields, err := r.reader.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return Record{}, &RowError{Row: parseErr.StartLine, Err: parseErr.Err}
+		}
+
+		return Record{}, err
+	}
+
+	row, _ := r.reader.FieldPos(0)
+
+	field := func(column string) string {
+		i, ok := r.columns[column]
+		if !ok {
+			return ""
+		}
+
+		return strings.TrimSpace(fields[i])
+	}
+
+	price, err := strconv.ParseInt(field("price"), 10, 64)
+	if err != nil {
+		return Record{}, &RowError{Row: row, Err: fmt.Errorf("price: %w", err)}
+	}
+
+	return Record{
+		Row:           row,
+		ServiceName:   field("service_name"),
+		Price:         price,
+		Currency:      field("currency"),
+		BillingPeriod: field("billing_period"),
+		UserID:        field("user_id"),
+		StartDate:     field("start_date"),
+		EndDate:       field("end_date"),
+	}, nil
+}
+
+// ndjsonReader reads records from newline-delimited JSON objects. Blank
+// lines are skipped.
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+	row     int
+}
+
+func newNDJSONReader(r io.Reader) *ndjsonReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	return &ndjsonReader{
+		scanner: scanner,
+	}
+}
+
+func (r *ndjsonReader) Read() (Record, error) {
+	for r.scanner.Scan() {
+		r.row++
+
+		line := bytes.TrimSpace(r.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.DisallowUnknownFields()
+
+		var record Record
+		if err := decoder.Decode(&record); err != nil {
+			return Record{}, &RowError{Row: r.row, Err: err}
+		}
+
+		if decoder.More() {
+			return Record{}, &RowError{Row: r.row, Err: errors.New("line contains more than one object")}
+		}
+
+		record.Row = r.row
+
+		return record, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return Record{}, fmt.Errorf("%w: line %d is too long", ErrInvalidFile, r.row+1)
+		}
+
+		return Record{}, err
+	}
+
+	return Record{}, io.EOF
+}
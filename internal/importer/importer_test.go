@@ -0,0 +1,210 @@
+package importer
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// result is a record or a row error read from a file, reduced to what the
+// tests compare.
+type result struct {
+	row         int
+	serviceName string
+	price       int64
+	endDate     string
+	err         bool
+}
+
+func readAll(t *testing.T, reader Reader) ([]result, error) {
+	t.Helper()
+
+	var results []result
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return results, nil
+		}
+
+		var rowError *RowError
+		if errors.As(err, &rowError) {
+			results = append(results, result{row: rowError.Row, err: true})
+
+			continue
+		}
+
+		if err != nil {
+			return results, err
+		}
+
+		results = append(results, result{row: record.Row, serviceName: record.ServiceName, price: record.Price, endDate: record.EndDate})
+	}
+}
+
+func TestReader(t *testing.T) {
+	const userID = "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+
+	tests := []struct {
+		name    string
+		format  Format
+		input   string
+		results []result
+		err     error
+	}{
+		{
+			name:   "csv",
+			format: FormatCSV,
+			input: "service_name,price,user_id,start_date,end_date\n" +
+				"Yandex Plus,400," + userID + ",07-2025,\n" +
+				"Netflix, 999 ," + userID + ",08-2025,12-2025\n",
+			results: []result{
+				{row: 2, serviceName: "Yandex Plus", price: 400},
+				{row: 3, serviceName: "Netflix", price: 999, endDate: "12-2025"},
+			},
+		},
+		{
+			name:   "csv header with byte order mark, other case and order",
+			format: FormatCSV,
+			input: "\ufeffUser_ID, Start_Date, Price, Service_Name\n" +
+				userID + ",07-2025,400,Yandex Plus\n",
+			results: []result{
+				{row: 2, serviceName: "Yandex Plus", price: 400},
+			},
+		},
+		{
+			name:   "csv export with id column",
+			format: FormatCSV,
+			input: "id,service_name,price,user_id,start_date\n" +
+				"1f0b7a9e-0d2c-4c38-9a5e-9d6a1b2c3d4e,Yandex Plus,400," + userID + ",07-2025\n",
+			results: []result{
+				{row: 2, serviceName: "Yandex Plus", price: 400},
+			},
+		},
+		{
+			name:   "csv quoted field over several lines",
+			format: FormatCSV,
+			input: "service_name,price,user_id,start_date\n" +
+				"\"Yandex\nPlus\",400," + userID + ",07-2025\n" +
+				"Netflix,999," + userID + ",08-2025\n",
+			results: []result{
+				{row: 2, serviceName: "Yandex\nPlus", price: 400},
+				{row: 4, serviceName: "Netflix", price: 999},
+			},
+		},
+		{
+			name:   "csv rows that can not be read",
+			format: FormatCSV,
+			input: "service_name,price,user_id,start_date\n" +
+				"Yandex Plus,free," + userID + ",07-2025\n" +
+				"Netflix,999," + userID + "\n" +
+				"Spotify,299," + userID + ",09-2025\n",
+			results: []result{
+				{row: 2, err: true},
+				{row: 3, err: true},
+				{row: 4, serviceName: "Spotify", price: 299},
+			},
+		},
+		{
+			name:   "csv without header",
+			format: FormatCSV,
+			input:  "",
+			err:    ErrInvalidFile,
+		},
+		{
+			name:   "csv unknown column",
+			format: FormatCSV,
+			input:  "service_name,price,user_id,start_date,discount\n",
+			err:    ErrInvalidFile,
+		},
+		{
+			name:   "csv duplicated column",
+			format: FormatCSV,
+			input:  "service_name,price,price,user_id,start_date\n",
+			err:    ErrInvalidFile,
+		},
+		{
+			name:   "csv missing required column",
+			format: FormatCSV,
+			input:  "service_name,price,user_id\n",
+			err:    ErrInvalidFile,
+		},
+		{
+			name:   "ndjson",
+			format: FormatNDJSON,
+			input: `{"service_name":"Yandex Plus","price":400,"user_id":"` + userID + `","start_date":"07-2025"}` + "\n" +
+				"\n" +
+				`  {"service_name":"Netflix","price":999,"user_id":"` + userID + `","start_date":"08-2025","end_date":"12-2025"}`,
+			results: []result{
+				{row: 1, serviceName: "Yandex Plus", price: 400},
+				{row: 3, serviceName: "Netflix", price: 999, endDate: "12-2025"},
+			},
+		},
+		{
+			name:   "ndjson lines that can not be read",
+			format: FormatNDJSON,
+			input: `{"service_name":"Yandex Plus","price":"400"}` + "\n" +
+				`{"service_name":"Netflix","discount":10}` + "\n" +
+				`{"service_name":"Spotify"} {"service_name":"Kion"}` + "\n" +
+				`{"service_name":` + "\n" +
+				`{"service_name":"Okko","price":399}` + "\n",
+			results: []result{
+				{row: 1, err: true},
+				{row: 2, err: true},
+				{row: 3, err: true},
+				{row: 4, err: true},
+				{row: 5, serviceName: "Okko", price: 399},
+			},
+		},
+		{
+			name:   "ndjson line too long",
+			format: FormatNDJSON,
+			input:  `{"service_name":"` + strings.Repeat("a", maxLineSize) + `"}`,
+			err:    ErrInvalidFile,
+		},
+	}
+
+	for _, tt := range tests {
+		reader, err := NewReader(strings.NewReader(tt.input), tt.format)
+		if err == nil {
+			var results []result
+
+			results, err = readAll(t, reader)
+			if err == nil && !slices.Equal(results, tt.results) {
+				t.Errorf("%s: results = %+v, want %+v", tt.name, results, tt.results)
+			}
+		}
+
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestNewReaderUnsupportedFormat(t *testing.T) {
+	if _, err := NewReader(strings.NewReader(""), "xml"); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("err = %v, want %v", err, ErrUnsupportedFormat)
+	}
+}
+
+func TestFormatFromMediaType(t *testing.T) {
+	tests := []struct {
+		mediaType string
+		want      Format
+	}{
+		{"text/csv", FormatCSV},
+		{"text/csv; charset=utf-8", FormatCSV},
+		{"application/x-ndjson", FormatNDJSON},
+		{"application/jsonl", FormatNDJSON},
+		{"application/json", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := FormatFromMediaType(tt.mediaType); got != tt.want {
+			t.Errorf("%q: format = %q, want %q", tt.mediaType, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,3 @@
+// Package logging provides functionality for logging with the fields of the
+// request being served
+package logging
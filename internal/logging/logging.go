@@ -0,0 +1,36 @@
+package logging
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type contextKey struct{}
+
+func WithLogger(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger stored in ctx, or the global logger if
+// there is none.
+func FromContext(ctx context.Context) *zap.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*zap.Logger); ok {
+		return logger
+	}
+
+	return zap.L()
+}
+
+type elapsed time.Time
+
+func (e elapsed) String() string {
+	return time.Since(time.Time(e)).String()
+}
+
+// Latency is a field with the time elapsed since start, measured when the
+// entry is written rather than when the field is created.
+func Latency(start time.Time) zap.Field {
+	return zap.Stringer("latency", elapsed(start))
+}
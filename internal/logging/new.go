@@ -0,0 +1,120 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mirrorblade/subscriptions/internal/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+	OutputStdout = "stdout"
+	OutputStderr = "stderr"
+	OutputFile   = "file"
+
+	EncodingJSON    = "json"
+	EncodingConsole = "console"
+)
+
+// New creates the logger of config. Its level can be changed at runtime
+// through the returned level. Development loggers also record stack traces
+// of errors.
+func New(config config.Log, development bool) (*zap.Logger, zap.AtomicLevel, error) {
+	level := zap.NewAtomicLevel()
+
+	if config.Level != "" {
+		if err := level.UnmarshalText([]byte(config.Level)); err != nil {
+			return nil, level, err
+		}
+	}
+
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.TimeKey = "timestamp"
+	encoderConfig.EncodeTime = zapcore.RFC3339TimeEncoder
+
+	var encoder zapcore.Encoder
+
+	switch config.Encoding {
+	case "", EncodingJSON:
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	case EncodingConsole:
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	default:
+		return nil, level, fmt.Errorf("unknown log encoding %q", config.Encoding)
+	}
+
+	// Outputs set through the environment come as one comma-separated
+	// string.
+	var outputs []string
+	for _, output := range config.Outputs {
+		for output := range strings.SplitSeq(output, ",") {
+			if output = strings.TrimSpace(output); output != "" {
+				outputs = append(outputs, output)
+			}
+		}
+	}
+
+	if len(outputs) == 0 {
+		outputs = []string{OutputStdout}
+	}
+
+	writers := make([]zapcore.WriteSyncer, 0, len(outputs))
+
+	for _, output := range outputs {
+		switch output {
+		case OutputStdout:
+			writers = append(writers, zapcore.Lock(os.Stdout))
+		case OutputStderr:
+			writers = append(writers, zapcore.Lock(os.Stderr))
+		case OutputFile:
+			if config.File.Path == "" {
+				return nil, level, fmt.Errorf("log file path is required for the file output")
+			}
+
+			if config.File.MaxAge < 0 {
+				return nil, level, fmt.Errorf("log file max age %s is negative", config.File.MaxAge)
+			}
+
+			writers = append(writers, zapcore.AddSync(&lumberjack.Logger{
+				Filename:   config.File.Path,
+				MaxSize:    config.File.MaxSize,
+				MaxBackups: config.File.MaxBackups,
+				MaxAge:     maxAgeDays(config.File.MaxAge),
+				Compress:   config.File.Compress,
+			}))
+		default:
+			return nil, level, fmt.Errorf("unknown log output %q", output)
+		}
+	}
+
+	core := zapcore.NewCore(encoder, zapcore.NewMultiWriteSyncer(writers...), level)
+
+	if sampling := config.Sampling; sampling.Initial > 0 {
+		tick := sampling.Tick
+		if tick <= 0 {
+			tick = time.Second
+		}
+
+		core = zapcore.NewSamplerWithOptions(core, tick, sampling.Initial, sampling.Thereafter)
+	}
+
+	options := []zap.Option{zap.AddCaller()}
+	if development {
+		options = append(options, zap.Development(), zap.AddStacktrace(zap.ErrorLevel))
+	}
+
+	return zap.New(core, options...), level, nil
+}
+
+// maxAgeDays rounds age up to whole days, so that an age shorter than a day
+// still removes old files: lumberjack never removes files for zero days.
+func maxAgeDays(age time.Duration) int {
+	const day = 24 * time.Hour
+
+	return int((age + day - 1) / day)
+}
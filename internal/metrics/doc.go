@@ -0,0 +1,3 @@
+// Package metrics provides functionality for exposing Prometheus metrics of
+// the HTTP server, the repositories and the database pool
+package metrics
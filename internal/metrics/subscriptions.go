@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mirrorblade/subscriptions/internal/domain"
+	"github.com/mirrorblade/subscriptions/internal/repository"
+)
+
+const subscriptionsRepository = "subscriptions"
+
+// Subscriptions times the calls of a subscriptions repository.
+type Subscriptions struct {
+	subscriptions repository.Subscriptions
+
+	metrics *Metrics
+}
+
+func NewSubscriptions(subscriptions repository.Subscriptions, metrics *Metrics) *Subscriptions {
+	return &Subscriptions{
+		subscriptions: subscriptions,
+		metrics:       metrics,
+	}
+}
+
+func (s *Subscriptions) GetByID(context context.Context, id uuid.UUID, includeDeleted bool) (subscription domain.Subscription, err error) {
+	defer s.observe("GetByID", time.Now(), &err)
+
+	return s.subscriptions.GetByID(context, id, includeDeleted)
+}
+
+func (s *Subscriptions) GetList(context context.Context, parameters repository.ListParameters) (subscriptions []domain.Subscription, err error) {
+	defer s.observe("GetList", time.Now(), &err)
+
+	return s.subscriptions.GetList(context, parameters)
+}
+
+func (s *Subscriptions) ForEach(context context.Context, parameters repository.ListParameters, fn func(domain.Subscription) error) (err error) {
+	defer s.observe("ForEach", time.Now(), &err)
+
+	return s.subscriptions.ForEach(context, parameters, fn)
+}
+
+func (s *Subscriptions) Search(context context.Context, parameters repository.SearchParameters) (subscriptions []domain.Subscription, total int64, err error) {
+	defer s.observe("Search", time.Now(), &err)
+
+	return s.subscriptions.Search(context, parameters)
+}
+
+func (s *Subscriptions) GetListInPeriodByUserID(context context.Context, userID uuid.UUID, parameters repository.GetSumParameters) (subscriptions []domain.Subscription, err error) {
+	defer s.observe("GetListInPeriodByUserID", time.Now(), &err)
+
+	return s.subscriptions.GetListInPeriodByUserID(context, userID, parameters)
+}
+
+func (s *Subscriptions) Create(context context.Context, subscription domain.Subscription) (created domain.Subscription, err error) {
+	defer s.observe("Create", time.Now(), &err)
+
+	return s.subscriptions.Create(context, subscription)
+}
+
+func (s *Subscriptions) UpdateByID(context context.Context, id uuid.UUID, versions []int64, parameters repository.UpdateParameters) (subscription domain.Subscription, err error) {
+	defer s.observe("UpdateByID", time.Now(), &err)
+
+	return s.subscriptions.UpdateByID(context, id, versions, parameters)
+}
+
+func (s *Subscriptions) DeleteByID(context context.Context, id uuid.UUID, versions []int64) (err error) {
+	defer s.observe("DeleteByID", time.Now(), &err)
+
+	return s.subscriptions.DeleteByID(context, id, versions)
+}
+
+func (s *Subscriptions) RestoreByID(context context.Context, id uuid.UUID) (subscription domain.Subscription, err error) {
+	defer s.observe("RestoreByID", time.Now(), &err)
+
+	return s.subscriptions.RestoreByID(context, id)
+}
+
+func (s *Subscriptions) PurgeDeleted(context context.Context, before time.Time) (count int64, err error) {
+	defer s.observe("PurgeDeleted", time.Now(), &err)
+
+	return s.subscriptions.PurgeDeleted(context, before)
+}
+
+func (s *Subscriptions) Batch(context context.Context, operations []repository.BatchOperation) (subscriptions []domain.Subscription, err error) {
+	defer s.observe("Batch", time.Now(), &err)
+
+	return s.subscriptions.Batch(context, operations)
+}
+
+func (s *Subscriptions) observe(method string, start time.Time, err *error) {
+	s.metrics.ObserveQuery(subscriptionsRepository, method, start, *err)
+}
@@ -0,0 +1,3 @@
+// Package ratelimit provides functionality for throttling requests with
+// token buckets
+package ratelimit
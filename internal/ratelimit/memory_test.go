@@ -0,0 +1,70 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreRefill(t *testing.T) {
+	limit := Limit{Requests: 60, Period: time.Minute, Burst: 2}
+
+	tests := []struct {
+		name      string
+		elapsed   time.Duration
+		allowed   bool
+		remaining int
+	}{
+		{"full bucket", 0, true, 1},
+		{"last token", 0, true, 0},
+		{"empty bucket", 0, false, 0},
+		{"half a token", 500 * time.Millisecond, false, 0},
+		{"refilled token", 500 * time.Millisecond, true, 0},
+		{"capped at burst", time.Hour, true, 1},
+	}
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	for _, tt := range tests {
+		now = now.Add(tt.elapsed)
+
+		result, err := store.Take(context.Background(), "client", limit)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		if result.Allowed != tt.allowed || result.Remaining != tt.remaining {
+			t.Errorf("%s: allowed = %t, remaining = %d, want %t, %d", tt.name, result.Allowed, result.Remaining, tt.allowed, tt.remaining)
+		}
+
+		if !result.Allowed && result.RetryAfter <= 0 {
+			t.Errorf("%s: retry after = %s, want positive", tt.name, result.RetryAfter)
+		}
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	limit := Limit{Requests: 60, Period: time.Minute, Burst: 2}
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	if _, err := store.Take(context.Background(), "client", limit); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(sweepInterval)
+
+	if _, err := store.Take(context.Background(), "other", limit); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := store.buckets["client"]; ok {
+		t.Error("refilled bucket was not swept")
+	}
+}
@@ -0,0 +1,142 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mirrorblade/subscriptions/internal/config"
+)
+
+// Limit allows Requests per Period on average, in bursts of up to Burst
+// requests.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+// Rate is the number of tokens added to a bucket per second.
+func (l Limit) Rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// Result is the state of a bucket after a request took a token from it.
+// RetryAfter is set when the request was not allowed.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Store keeps the buckets of the limiter. The memory store serves a single
+// instance; instances that share limits need a store with a shared backend.
+type Store interface {
+	Take(context context.Context, key string, limit Limit) (Result, error)
+}
+
+type route struct {
+	method string
+	path   string
+}
+
+// Limiter throttles every client with a bucket of the default limit, and
+// with a separate bucket for each route that has a limit of its own. IPs
+// have buckets of their own limit, so that requests can be throttled before
+// the client is known.
+type Limiter struct {
+	store Store
+
+	limit  Limit
+	routes map[route]Limit
+	ip     Limit
+}
+
+// New creates a limiter of the limits in config. A limit without requests
+// lets requests through, so a route can be exempted from the default limit.
+func New(store Store, config config.RateLimit) (*Limiter, error) {
+	limit, err := newLimit(config.Requests, config.Period, config.Burst)
+	if err != nil {
+		return nil, err
+	}
+
+	ip, err := newLimit(config.IP.Requests, config.IP.Period, config.IP.Burst)
+	if err != nil {
+		return nil, fmt.Errorf("ip: %w", err)
+	}
+
+	limiter := &Limiter{
+		store:  store,
+		limit:  limit,
+		routes: make(map[route]Limit, len(config.Routes)),
+		ip:     ip,
+	}
+
+	for _, r := range config.Routes {
+		if r.Method == "" || r.Path == "" {
+			return nil, fmt.Errorf("route %q %q: method and path are required", r.Method, r.Path)
+		}
+
+		limit, err := newLimit(r.Requests, r.Period, r.Burst)
+		if err != nil {
+			return nil, fmt.Errorf("route %s %s: %w", r.Method, r.Path, err)
+		}
+
+		limiter.routes[route{strings.ToUpper(r.Method), r.Path}] = limit
+	}
+
+	return limiter, nil
+}
+
+func newLimit(requests int, period time.Duration, burst int) (Limit, error) {
+	if requests < 0 || burst < 0 {
+		return Limit{}, fmt.Errorf("requests and burst can not be negative")
+	}
+
+	if requests > 0 && period <= 0 {
+		return Limit{}, fmt.Errorf("period must be positive")
+	}
+
+	if burst == 0 {
+		burst = requests
+	}
+
+	return Limit{Requests: requests, Period: period, Burst: burst}, nil
+}
+
+// Take takes a token from the bucket of client for the route of method and
+// path. It reports false if no limit applies to the route.
+func (l *Limiter) Take(context context.Context, client, method, path string) (Result, bool, error) {
+	key := client
+
+	limit, ok := l.routes[route{method, path}]
+	if ok {
+		key += " " + method + " " + path
+	} else {
+		limit = l.limit
+	}
+
+	return l.take(context, key, limit)
+}
+
+// TakeIP takes a token from the bucket of ip. It reports false if IPs are
+// not limited.
+func (l *Limiter) TakeIP(context context.Context, ip string) (Result, bool, error) {
+	return l.take(context, "ip:"+ip, l.ip)
+}
+
+func (l *Limiter) take(context context.Context, key string, limit Limit) (Result, bool, error) {
+	if limit.Requests == 0 {
+		return Result{}, false, nil
+	}
+
+	result, err := l.store.Take(context, key, limit)
+	if err != nil {
+		return Result{}, false, err
+	}
+
+	return result, true, nil
+}
@@ -0,0 +1,3 @@
+// Package rbac provides functionality for role-based access control of
+// subscription operations
+package rbac
@@ -0,0 +1,117 @@
+package rbac
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+	"github.com/mirrorblade/subscriptions/internal/domain"
+)
+
+type Operation string
+
+const (
+	OperationRead      Operation = "read"
+	OperationAggregate Operation = "aggregate"
+	OperationCreate    Operation = "create"
+	OperationUpdate    Operation = "update"
+	OperationDelete    Operation = "delete"
+	OperationRestore   Operation = "restore"
+)
+
+func (o Operation) Valid() bool {
+	switch o {
+	case OperationRead, OperationAggregate, OperationCreate, OperationUpdate, OperationDelete, OperationRestore:
+		return true
+	}
+
+	return false
+}
+
+// Policy maps roles to the operations they allow. Principals without roles
+// get the default roles, and admins are allowed every operation. Users may
+// only operate on their own subscriptions unless one of their roles is in
+// AllUsers.
+type Policy struct {
+	DefaultRoles []string               `koanf:"default_roles"`
+	Roles        map[string][]Operation `koanf:"roles"`
+	AllUsers     []string               `koanf:"all_users"`
+}
+
+// Load reads a policy from the YAML file at path.
+func Load(path string) (*Policy, error) {
+	k := koanf.New(".")
+
+	if err := k.Load(file.Provider(path), yaml.Parser()); err != nil {
+		return nil, err
+	}
+
+	policy := new(Policy)
+
+	if err := k.Unmarshal("", policy); err != nil {
+		return nil, err
+	}
+
+	for role, operations := range policy.Roles {
+		for _, operation := range operations {
+			if !operation.Valid() {
+				return nil, fmt.Errorf("role %s: unknown operation %q", role, operation)
+			}
+		}
+	}
+
+	for _, role := range policy.DefaultRoles {
+		if _, ok := policy.Roles[role]; !ok {
+			return nil, fmt.Errorf("default role %s is not defined", role)
+		}
+	}
+
+	for _, role := range policy.AllUsers {
+		if _, ok := policy.Roles[role]; !ok {
+			return nil, fmt.Errorf("all users role %s is not defined", role)
+		}
+	}
+
+	return policy, nil
+}
+
+func (p *Policy) Allows(principal domain.Principal, operation Operation) bool {
+	if principal.Admin() {
+		return true
+	}
+
+	for _, role := range p.roles(principal) {
+		if slices.Contains(p.Roles[role], operation) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AllowsAllUsers reports whether principal may operate on the subscriptions
+// of every user rather than only on its own ones. Admins and service callers
+// may regardless of their roles.
+func (p *Policy) AllowsAllUsers(principal domain.Principal) bool {
+	if principal.AllUsers() {
+		return true
+	}
+
+	for _, role := range p.roles(principal) {
+		if slices.Contains(p.AllUsers, role) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *Policy) roles(principal domain.Principal) []string {
+	if len(principal.Roles) == 0 {
+		return p.DefaultRoles
+	}
+
+	return principal.Roles
+}
@@ -0,0 +1,49 @@
+package rbac
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mirrorblade/subscriptions/internal/domain"
+)
+
+func TestAllowsAllUsers(t *testing.T) {
+	policy := &Policy{
+		DefaultRoles: []string{"editor"},
+		Roles: map[string][]Operation{
+			"editor":  {OperationRead, OperationUpdate},
+			"support": {OperationRead, OperationRestore},
+		},
+		AllUsers: []string{"support"},
+	}
+
+	tests := []struct {
+		name      string
+		principal domain.Principal
+		want      bool
+	}{
+		{"default roles", domain.Principal{UserID: uuid.New()}, false},
+		{"own role", domain.Principal{UserID: uuid.New(), Roles: []string{"editor"}}, false},
+		{"all users role", domain.Principal{UserID: uuid.New(), Roles: []string{"editor", "support"}}, true},
+		{"admin", domain.Principal{UserID: uuid.New(), Scopes: []string{domain.ScopeAdmin}}, true},
+		{"api key", domain.Principal{APIKeyID: uuid.New(), Roles: []string{"editor"}}, true},
+	}
+
+	for _, tt := range tests {
+		if got := policy.AllowsAllUsers(tt.principal); got != tt.want {
+			t.Errorf("%s: AllowsAllUsers = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	policy, err := Load("../../configs/policy.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	support := domain.Principal{UserID: uuid.New(), Roles: []string{"support"}}
+	if !policy.AllowsAllUsers(support) {
+		t.Error("support can not access every user")
+	}
+}
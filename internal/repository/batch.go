@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/mirrorblade/subscriptions/internal/domain"
+)
+
+type BatchOperationType string
+
+const (
+	BatchOperationCreate BatchOperationType = "create"
+	BatchOperationUpdate BatchOperationType = "update"
+	BatchOperationDelete BatchOperationType = "delete"
+)
+
+func (t BatchOperationType) Valid() bool {
+	switch t {
+	case BatchOperationCreate, BatchOperationUpdate, BatchOperationDelete:
+		return true
+	}
+
+	return false
+}
+
+// BatchOperation is a single change in a batch. Subscription is used by
+// create operations, ID and Version by update and delete operations and
+// Parameters by update operations only.
+type BatchOperation struct {
+	Type         BatchOperationType
+	ID           uuid.UUID
+	Version      int64
+	Subscription domain.Subscription
+	Parameters   UpdateParameters
+}
+
+// Versions returns the versions the operation is conditional on: none, so
+// any version, for a zero Version.
+func (o BatchOperation) Versions() []int64 {
+	if o.Version == 0 {
+		return nil
+	}
+
+	return []int64{o.Version}
+}
+
+type BatchResult struct {
+	Subscription *domain.Subscription
+	Err          error
+}
+
+// BatchError reports the operation that made a batch fail.
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("batch operation %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mirrorblade/subscriptions/internal/domain"
+)
+
+// Cursor points at the last subscription of a page in keyset pagination.
+// Value holds the text form of the sort column of that subscription.
+type Cursor struct {
+	SortBy     SortField `json:"s"`
+	Descending bool      `json:"d,omitempty"`
+	Value      string    `json:"v"`
+	ID         uuid.UUID `json:"i"`
+}
+
+func NewCursor(subscription domain.Subscription, sortBy SortField, descending bool) Cursor {
+	cursor := Cursor{
+		SortBy:     sortBy,
+		Descending: descending,
+		ID:         subscription.ID,
+	}
+
+	switch sortBy {
+	case SortByPrice:
+		cursor.Value = strconv.FormatInt(subscription.Price, 10)
+	case SortByServiceName:
+		cursor.Value = subscription.ServiceName
+	default:
+		cursor.Value = subscription.StartDate.Format("2006-01-02")
+	}
+
+	return cursor
+}
+
+// DecodeCursor decodes a cursor encoded by Encode. Cursors come from
+// clients, so the value is checked to be of the type of the sort column.
+func DecodeCursor(token string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Cursor{}, domain.ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || !cursor.SortBy.Valid() || !cursor.validValue() {
+		return Cursor{}, domain.ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
+
+func (c Cursor) validValue() bool {
+	var err error
+
+	switch c.SortBy {
+	case SortByPrice:
+		_, err = strconv.ParseInt(c.Value, 10, 64)
+	case SortByServiceName:
+		return true
+	default:
+		_, err = time.Parse("2006-01-02", c.Value)
+	}
+
+	return err == nil
+}
+
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mirrorblade/subscriptions/internal/domain"
+)
+
+func TestDecodeCursor(t *testing.T) {
+	subscription := domain.Subscription{
+		ID:          uuid.New(),
+		ServiceName: "Yandex Plus",
+		Price:       400,
+		StartDate:   time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"start date", NewCursor(subscription, SortByStartDate, false).Encode(), nil},
+		{"price", NewCursor(subscription, SortByPrice, true).Encode(), nil},
+		{"service name", NewCursor(subscription, SortByServiceName, false).Encode(), nil},
+		{"not base64", "!", domain.ErrInvalidCursor},
+		{"not json", base64.RawURLEncoding.EncodeToString([]byte("cursor")), domain.ErrInvalidCursor},
+		{"unknown sort", Cursor{SortBy: "user_id", Value: "1", ID: subscription.ID}.Encode(), domain.ErrInvalidCursor},
+		{"tampered start date", Cursor{SortBy: SortByStartDate, Value: "07-2025", ID: subscription.ID}.Encode(), domain.ErrInvalidCursor},
+		{"tampered price", Cursor{SortBy: SortByPrice, Value: "1e3", ID: subscription.ID}.Encode(), domain.ErrInvalidCursor},
+		{"price out of range", Cursor{SortBy: SortByPrice, Value: "99999999999999999999", ID: subscription.ID}.Encode(), domain.ErrInvalidCursor},
+	}
+
+	for _, tt := range tests {
+		_, err := DecodeCursor(tt.token)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
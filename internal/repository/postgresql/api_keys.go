@@ -0,0 +1,90 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mirrorblade/subscriptions/internal/domain"
+)
+
+type APIKeys struct {
+	pool *pgxpool.Pool
+
+	tableName string
+}
+
+func NewAPIKeys(pool *pgxpool.Pool, tableName string) *APIKeys {
+	return &APIKeys{
+		pool:      pool,
+		tableName: tableName,
+	}
+}
+
+func (a *APIKeys) Create(context context.Context, key domain.APIKey) (domain.APIKey, error) {
+	query := fmt.Sprintf(`INSERT INTO %s (id, name, prefix, hash, tenant_id, scopes, roles, expires_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING *`, a.tableName)
+
+	rows, err := a.pool.Query(context, query, key.ID, key.Name, key.Prefix, key.Hash, key.TenantID, key.Scopes, key.Roles, key.ExpiresAt)
+	if err != nil {
+		return domain.APIKey{}, err
+	}
+
+	return pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.APIKey])
+}
+
+func (a *APIKeys) GetByHash(context context.Context, hash string) (domain.APIKey, error) {
+	query := fmt.Sprintf("SELECT * FROM %s WHERE hash = $1", a.tableName)
+
+	rows, err := a.pool.Query(context, query, hash)
+	if err != nil {
+		return domain.APIKey{}, err
+	}
+
+	key, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.APIKey])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.APIKey{}, domain.ErrAPIKeyNotFound
+		}
+
+		return domain.APIKey{}, err
+	}
+
+	return key, nil
+}
+
+func (a *APIKeys) GetList(context context.Context) ([]domain.APIKey, error) {
+	query := fmt.Sprintf("SELECT * FROM %s ORDER BY created_at, id", a.tableName)
+
+	rows, err := a.pool.Query(context, query)
+	if err != nil {
+		return []domain.APIKey{}, err
+	}
+
+	keys, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.APIKey])
+	if err != nil {
+		return []domain.APIKey{}, err
+	}
+
+	return keys, nil
+}
+
+// Revoke revokes the key with id. Keys that are already revoked are reported
+// as not found.
+func (a *APIKeys) Revoke(context context.Context, id uuid.UUID) error {
+	query := fmt.Sprintf("UPDATE %s SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL", a.tableName)
+
+	commandTag, err := a.pool.Exec(context, query, id)
+	if err != nil {
+		return err
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
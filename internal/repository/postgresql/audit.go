@@ -0,0 +1,48 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mirrorblade/subscriptions/internal/domain"
+)
+
+type Audit struct {
+	pool *pgxpool.Pool
+
+	tableName string
+}
+
+func NewAudit(pool *pgxpool.Pool, tableName string) *Audit {
+	return &Audit{
+		pool:      pool,
+		tableName: tableName,
+	}
+}
+
+func (a *Audit) GetListBySubscriptionID(context context.Context, subscriptionID uuid.UUID) ([]domain.AuditRecord, error) {
+	tenant, args := tenantCondition(context, []any{subscriptionID})
+
+	query := fmt.Sprintf("SELECT * FROM %s WHERE subscription_id = $1 AND %s ORDER BY id", a.tableName, tenant)
+
+	var records []domain.AuditRecord
+
+	err := inTenant(context, a.pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(context, query, args...)
+		if err != nil {
+			return err
+		}
+
+		records, err = pgx.CollectRows(rows, pgx.RowToStructByName[domain.AuditRecord])
+
+		return err
+	})
+	if err != nil {
+		return []domain.AuditRecord{}, err
+	}
+
+	return records, nil
+}
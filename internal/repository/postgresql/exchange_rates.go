@@ -0,0 +1,60 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mirrorblade/subscriptions/internal/domain"
+)
+
+type ExchangeRates struct {
+	pool *pgxpool.Pool
+
+	tableName string
+}
+
+func NewExchangeRates(pool *pgxpool.Pool, tableName string) *ExchangeRates {
+	return &ExchangeRates{
+		pool:      pool,
+		tableName: tableName,
+	}
+}
+
+// GetRate returns the rate of the pair of from and to, or the inverse rate of
+// the reverse pair. Without either, the rate is derived from the rates of
+// both currencies in a common quote currency.
+func (e *ExchangeRates) GetRate(context context.Context, from, to domain.Currency) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+
+	query := fmt.Sprintf(`SELECT rate FROM (
+			SELECT 1 AS priority, rate::FLOAT8 AS rate FROM %[1]s WHERE base = $1 AND quote = $2
+			UNION ALL
+			SELECT 2, 1 / rate::FLOAT8 FROM %[1]s WHERE base = $2 AND quote = $1
+			UNION ALL
+			SELECT 3, f.rate::FLOAT8 / t.rate::FLOAT8 FROM %[1]s AS f JOIN %[1]s AS t ON t.quote = f.quote
+				WHERE f.base = $1 AND t.base = $2
+		) AS rates
+		ORDER BY priority
+		LIMIT 1`, e.tableName)
+
+	rows, err := e.pool.Query(context, query, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	rate, err := pgx.CollectExactlyOneRow(rows, pgx.RowTo[float64])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, domain.ErrExchangeRateNotFound
+		}
+
+		return 0, err
+	}
+
+	return rate, nil
+}
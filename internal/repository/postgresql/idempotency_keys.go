@@ -0,0 +1,125 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mirrorblade/subscriptions/internal/domain"
+)
+
+type IdempotencyKeys struct {
+	pool *pgxpool.Pool
+
+	tableName string
+}
+
+type idempotencyKeyRow struct {
+	Key         string
+	RequestHash string
+	Status      *int
+	Headers     http.Header
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+func NewIdempotencyKeys(pool *pgxpool.Pool, tableName string) *IdempotencyKeys {
+	return &IdempotencyKeys{
+		pool:      pool,
+		tableName: tableName,
+	}
+}
+
+func (i *IdempotencyKeys) Reserve(context context.Context, key, requestHash string, expiresAt time.Time) (domain.IdempotencyKey, bool, error) {
+	query := fmt.Sprintf(`INSERT INTO %[1]s AS t (key, request_hash, expires_at) VALUES ($1, $2, $3)
+		ON CONFLICT (key) DO UPDATE SET request_hash = EXCLUDED.request_hash, status = NULL, headers = NULL, body = NULL,
+			created_at = now(), expires_at = EXCLUDED.expires_at
+		WHERE t.expires_at < now()
+		RETURNING *`, i.tableName)
+
+	rows, err := i.pool.Query(context, query, key, requestHash, expiresAt)
+	if err != nil {
+		return domain.IdempotencyKey{}, false, err
+	}
+
+	row, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[idempotencyKeyRow])
+	if err == nil {
+		return row.idempotencyKey(), true, nil
+	}
+
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return domain.IdempotencyKey{}, false, err
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s WHERE key = $1", i.tableName)
+
+	rows, err = i.pool.Query(context, query, key)
+	if err != nil {
+		return domain.IdempotencyKey{}, false, err
+	}
+
+	row, err = pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[idempotencyKeyRow])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.IdempotencyKey{}, false, domain.ErrIdempotencyKeyInProgress
+		}
+
+		return domain.IdempotencyKey{}, false, err
+	}
+
+	return row.idempotencyKey(), false, nil
+}
+
+func (i *IdempotencyKeys) Complete(context context.Context, key string, response domain.IdempotentResponse) error {
+	query := fmt.Sprintf("UPDATE %s SET status = $1, headers = $2, body = $3 WHERE key = $4", i.tableName)
+
+	if _, err := i.pool.Exec(context, query, response.Status, response.Headers, response.Body, key); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (i *IdempotencyKeys) Release(context context.Context, key string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE key = $1 AND status IS NULL", i.tableName)
+
+	if _, err := i.pool.Exec(context, query, key); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (i *IdempotencyKeys) PurgeExpired(context context.Context, before time.Time) (int64, error) {
+	query := fmt.Sprintf("DELETE FROM %s WHERE expires_at < $1", i.tableName)
+
+	commandTag, err := i.pool.Exec(context, query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return commandTag.RowsAffected(), nil
+}
+
+func (r idempotencyKeyRow) idempotencyKey() domain.IdempotencyKey {
+	key := domain.IdempotencyKey{
+		Key:         r.Key,
+		RequestHash: r.RequestHash,
+		ExpiresAt:   r.ExpiresAt,
+	}
+
+	if r.Status != nil {
+		key.Response = &domain.IdempotentResponse{
+			Status:  *r.Status,
+			Headers: r.Headers,
+			Body:    r.Body,
+		}
+	}
+
+	return key
+}
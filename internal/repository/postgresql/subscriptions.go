@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -17,25 +18,39 @@ import (
 type Subscriptions struct {
 	pool *pgxpool.Pool
 
-	tableName string
+	tableName      string
+	auditTableName string
 }
 
-func NewSubscriptions(pool *pgxpool.Pool, tableName string) *Subscriptions {
+func NewSubscriptions(pool *pgxpool.Pool, tableName, auditTableName string) *Subscriptions {
 	return &Subscriptions{
-		pool:      pool,
-		tableName: tableName,
+		pool:           pool,
+		tableName:      tableName,
+		auditTableName: auditTableName,
 	}
 }
 
-func (s *Subscriptions) GetByID(context context.Context, id uuid.UUID) (domain.Subscription, error) {
-	query := fmt.Sprintf("SELECT * FROM %s WHERE id = $1", s.tableName)
+func (s *Subscriptions) GetByID(context context.Context, id uuid.UUID, includeDeleted bool) (domain.Subscription, error) {
+	tenant, args := tenantCondition(context, []any{id})
 
-	rows, err := s.pool.Query(context, query, id)
-	if err != nil {
-		return domain.Subscription{}, err
+	query := fmt.Sprintf("SELECT * FROM %s WHERE id = $1 AND %s", s.tableName, tenant)
+
+	if !includeDeleted {
+		query += " AND deleted_at IS NULL"
 	}
 
-	subscription, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.Subscription])
+	var subscription domain.Subscription
+
+	err := inTenant(context, s.pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(context, query, args...)
+		if err != nil {
+			return err
+		}
+
+		subscription, err = pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.Subscription])
+
+		return err
+	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return domain.Subscription{}, domain.ErrSubscriptionNotFound
@@ -47,35 +62,138 @@ func (s *Subscriptions) GetByID(context context.Context, id uuid.UUID) (domain.S
 	return subscription, nil
 }
 
-func (s *Subscriptions) GetListByUserID(context context.Context, userID uuid.UUID) ([]domain.Subscription, error) {
-	query := fmt.Sprintf("SELECT * FROM %s WHERE user_id = $1", s.tableName)
+func (s *Subscriptions) GetList(context context.Context, parameters repository.ListParameters) ([]domain.Subscription, error) {
+	query, args := s.listQuery(context, parameters)
+
+	var subscriptions []domain.Subscription
+
+	err := inTenant(context, s.pool, func(tx pgx.Tx) error {
+		var err error
+
+		subscriptions, err = s.query(context, tx, query, args)
 
-	rows, err := s.pool.Query(context, query, userID)
+		return err
+	})
 	if err != nil {
 		return []domain.Subscription{}, err
 	}
 
-	subscriptions, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.Subscription])
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return []domain.Subscription{}, domain.ErrUserNotFound
+	return subscriptions, nil
+}
+
+// ForEach calls fn for every subscription in the list as the rows arrive
+// from the database, without loading the whole list into memory.
+func (s *Subscriptions) ForEach(context context.Context, parameters repository.ListParameters, fn func(domain.Subscription) error) error {
+	query, args := s.listQuery(context, parameters)
+
+	return inTenant(context, s.pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(context, query, args...)
+		if err != nil {
+			return err
 		}
+		defer rows.Close()
 
-		return []domain.Subscription{}, err
+		for rows.Next() {
+			subscription, err := pgx.RowToStructByName[domain.Subscription](rows)
+			if err != nil {
+				return err
+			}
+
+			if err := fn(subscription); err != nil {
+				return err
+			}
+		}
+
+		return rows.Err()
+	})
+}
+
+func (s *Subscriptions) listQuery(context context.Context, parameters repository.ListParameters) (string, []any) {
+	tenant, args := tenantCondition(context, []any{})
+
+	query := fmt.Sprintf("SELECT * FROM %s WHERE %s", s.tableName, tenant)
+
+	conditions, args := listFilterConditions(parameters.Filter, args)
+	query += conditions
+
+	sortBy := parameters.SortBy
+	if !sortBy.Valid() {
+		sortBy = repository.SortByStartDate
 	}
 
-	if len(subscriptions) == 0 {
-		return []domain.Subscription{}, domain.ErrUserNotFound
+	order, comparison := "ASC", ">"
+	if parameters.Descending {
+		order, comparison = "DESC", "<"
 	}
 
-	return subscriptions, nil
+	if parameters.After != nil {
+		args = append(args, parameters.After.Value, parameters.After.ID)
+		query += fmt.Sprintf(" AND (%s, id) %s ($%d::%s, $%d)", sortBy, comparison, len(args)-1, sortColumnTypes[sortBy], len(args))
+	}
+
+	query += fmt.Sprintf(" ORDER BY %[1]s %[2]s, id %[2]s", sortBy, order)
+
+	if parameters.Limit > 0 {
+		args = append(args, parameters.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	return query, args
 }
 
-func (s *Subscriptions) GetPriceSumByUserID(context context.Context, userID uuid.UUID, parameters repository.GetSumParameters) (int64, error) {
-	query := fmt.Sprintf("SELECT SUM(price) FROM %s WHERE user_id = $1", s.tableName)
+func (s *Subscriptions) Search(context context.Context, parameters repository.SearchParameters) ([]domain.Subscription, int64, error) {
+	tenant, args := tenantCondition(context, []any{})
+	conditions, args := listFilterConditions(parameters.Filter, args)
+	conditions = " WHERE " + tenant + conditions
+
+	sortBy := parameters.SortBy
+	if !sortBy.Valid() {
+		sortBy = repository.SortByStartDate
+	}
+
+	order := "ASC"
+	if parameters.Descending {
+		order = "DESC"
+	}
+
+	var (
+		subscriptions []domain.Subscription
+		total         int64
+	)
 
-	args := []any{any(userID)}
-	idx := 2
+	err := inTenant(context, s.pool, func(tx pgx.Tx) error {
+		query := fmt.Sprintf("SELECT COUNT(*) FROM %s", s.tableName) + conditions
+
+		rows, err := tx.Query(context, query, args...)
+		if err != nil {
+			return err
+		}
+
+		total, err = pgx.CollectExactlyOneRow(rows, pgx.RowTo[int64])
+		if err != nil {
+			return err
+		}
+
+		query = fmt.Sprintf("SELECT * FROM %s", s.tableName) + conditions
+		query += fmt.Sprintf(" ORDER BY %[1]s %[2]s, id %[2]s LIMIT $%[3]d OFFSET $%[4]d", sortBy, order, len(args)+1, len(args)+2)
+
+		subscriptions, err = s.query(context, tx, query, append(args, parameters.Limit, parameters.Offset))
+
+		return err
+	})
+	if err != nil {
+		return []domain.Subscription{}, 0, err
+	}
+
+	return subscriptions, total, nil
+}
+
+func (s *Subscriptions) GetListInPeriodByUserID(context context.Context, userID uuid.UUID, parameters repository.GetSumParameters) ([]domain.Subscription, error) {
+	tenant, args := tenantCondition(context, []any{any(userID)})
+
+	query := fmt.Sprintf("SELECT * FROM %s WHERE user_id = $1 AND %s AND deleted_at IS NULL", s.tableName, tenant)
+
+	idx := len(args) + 1
 
 	if parameters.ServiceName != nil {
 		query += fmt.Sprintf(" AND service_name = $%d", idx)
@@ -83,35 +201,249 @@ func (s *Subscriptions) GetPriceSumByUserID(context context.Context, userID uuid
 		idx++
 	}
 	if parameters.FromDate != nil {
-		query += fmt.Sprintf(" AND start_date >= $%d", idx)
+		query += fmt.Sprintf(" AND (end_date IS NULL OR end_date >= $%d)", idx)
 		args = append(args, *parameters.FromDate)
 		idx++
 	}
 	if parameters.ToDate != nil {
-		query += fmt.Sprintf(" AND end_date <= $%d", idx)
+		query += fmt.Sprintf(" AND start_date <= $%d", idx)
 		args = append(args, *parameters.ToDate)
 		idx++
 	}
 
-	rows, err := s.pool.Query(context, query, args...)
+	query += " ORDER BY start_date, id"
+
+	var subscriptions []domain.Subscription
+
+	err := inTenant(context, s.pool, func(tx pgx.Tx) error {
+		var err error
+
+		subscriptions, err = s.query(context, tx, query, args)
+
+		return err
+	})
+	if err != nil {
+		return []domain.Subscription{}, err
+	}
+
+	return subscriptions, nil
+}
+
+func (s *Subscriptions) Create(context context.Context, subscription domain.Subscription) (domain.Subscription, error) {
+	query, args, err := s.createQuery(context, subscription)
 	if err != nil {
-		return 0, err
+		return domain.Subscription{}, err
 	}
 
-	sum, err := pgx.CollectExactlyOneRow(rows, pgx.RowTo[int64])
+	err = inTenant(context, s.pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(context, query, args...)
+		if err != nil {
+			return err
+		}
+
+		subscription, err = pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[domain.Subscription])
+
+		return err
+	})
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return 0, domain.ErrSubscriptionNotFound
+		return domain.Subscription{}, err
+	}
+
+	return subscription, nil
+}
+
+func (s *Subscriptions) UpdateByID(context context.Context, id uuid.UUID, versions []int64, parameters repository.UpdateParameters) (domain.Subscription, error) {
+	query, args, err := s.updateQuery(context, id, versions, parameters)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+
+	var subscription domain.Subscription
+
+	err = inTenant(context, s.pool, func(tx pgx.Tx) error {
+		subscriptions, err := s.query(context, tx, query, args)
+		if err != nil {
+			return err
+		}
+
+		if len(subscriptions) == 0 {
+			return s.missingError(context, tx, id)
 		}
 
+		subscription = subscriptions[0]
+
+		return nil
+	})
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+
+	return subscription, nil
+}
+
+func (s *Subscriptions) DeleteByID(context context.Context, id uuid.UUID, versions []int64) error {
+	query, args := s.deleteQuery(context, id, versions)
+
+	return inTenant(context, s.pool, func(tx pgx.Tx) error {
+		subscriptions, err := s.query(context, tx, query, args)
+		if err != nil {
+			return err
+		}
+
+		if len(subscriptions) == 0 {
+			return s.missingError(context, tx, id)
+		}
+
+		return nil
+	})
+}
+
+// Batch applies operations in a single transaction, sending them to the
+// database in one round trip. Either all operations are applied, or none
+// and the returned BatchError points at the operation that failed.
+func (s *Subscriptions) Batch(context context.Context, operations []repository.BatchOperation) ([]domain.Subscription, error) {
+	batch := &pgx.Batch{}
+
+	for i, operation := range operations {
+		var (
+			query string
+			args  []any
+			err   error
+		)
+
+		switch operation.Type {
+		case repository.BatchOperationCreate:
+			query, args, err = s.createQuery(context, operation.Subscription)
+		case repository.BatchOperationUpdate:
+			query, args, err = s.updateQuery(context, operation.ID, operation.Versions(), operation.Parameters)
+		case repository.BatchOperationDelete:
+			query, args = s.deleteQuery(context, operation.ID, operation.Versions())
+		default:
+			err = domain.ErrInvalidBatch
+		}
+
+		if err != nil {
+			return []domain.Subscription{}, &repository.BatchError{Index: i, Err: err}
+		}
+
+		batch.Queue(query, args...)
+	}
+
+	subscriptions := make([]domain.Subscription, 0, len(operations))
+
+	err := inTenant(context, s.pool, func(tx pgx.Tx) error {
+		results := tx.SendBatch(context, batch)
+
+		for i, operation := range operations {
+			rows, err := results.Query()
+			if err != nil {
+				results.Close()
+
+				return &repository.BatchError{Index: i, Err: err}
+			}
+
+			changed, err := pgx.CollectRows(rows, pgx.RowToStructByName[domain.Subscription])
+			if err != nil {
+				results.Close()
+
+				return &repository.BatchError{Index: i, Err: err}
+			}
+
+			if len(changed) == 0 {
+				if err := results.Close(); err != nil {
+					return err
+				}
+
+				return &repository.BatchError{Index: i, Err: s.missingError(context, tx, operation.ID)}
+			}
+
+			subscriptions = append(subscriptions, changed[0])
+		}
+
+		return results.Close()
+	})
+	if err != nil {
+		return []domain.Subscription{}, err
+	}
+
+	return subscriptions, nil
+}
+
+func (s *Subscriptions) RestoreByID(context context.Context, id uuid.UUID) (domain.Subscription, error) {
+	tenant, args := tenantCondition(context, []any{id})
+
+	query, args := s.auditedUpdate(context, domain.AuditActionRestore, "deleted_at = NULL", "id = $1 AND "+tenant+" AND deleted_at IS NOT NULL", args)
+
+	var subscription domain.Subscription
+
+	err := inTenant(context, s.pool, func(tx pgx.Tx) error {
+		subscriptions, err := s.query(context, tx, query, args)
+		if err != nil {
+			return err
+		}
+
+		if len(subscriptions) == 0 {
+			err := s.missingError(context, tx, id)
+			if errors.Is(err, domain.ErrVersionMismatch) {
+				return domain.ErrSubscriptionActive
+			}
+
+			return err
+		}
+
+		subscription = subscriptions[0]
+
+		return nil
+	})
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+
+	return subscription, nil
+}
+
+func (s *Subscriptions) PurgeDeleted(context context.Context, before time.Time) (int64, error) {
+	tenant, args := tenantCondition(context, []any{before})
+
+	mutation := fmt.Sprintf("DELETE FROM %s WHERE deleted_at < $1 AND %s RETURNING *", s.tableName, tenant)
+
+	query, args := s.audited(context, domain.AuditActionPurge, mutation, args, "COUNT(*)")
+
+	var count int64
+
+	err := inTenant(context, s.pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(context, query, args...)
+		if err != nil {
+			return err
+		}
+
+		count, err = pgx.CollectExactlyOneRow(rows, pgx.RowTo[int64])
+
+		return err
+	})
+	if err != nil {
 		return 0, err
 	}
 
-	return sum, nil
+	return count, nil
 }
 
-func (s *Subscriptions) Create(context context.Context, subscription domain.Subscription) error {
-	query := fmt.Sprintf("INSERT INTO %s (id, service_name, price, user_id, start_date, end_date) VALUES ($1, $2, $3, $4, $5, $6)", s.tableName)
+func (s *Subscriptions) query(context context.Context, tx pgx.Tx, query string, args []any) ([]domain.Subscription, error) {
+	rows, err := tx.Query(context, query, args...)
+	if err != nil {
+		return []domain.Subscription{}, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[domain.Subscription])
+}
+
+// createQuery builds the statement that creates subscription in the tenant
+// of context.
+func (s *Subscriptions) createQuery(context context.Context, subscription domain.Subscription) (string, []any, error) {
+	tenantID, ok := domain.TenantFromContext(context)
+	if !ok || tenantID == domain.AllTenants {
+		return "", nil, domain.ErrTenantRequired
+	}
 
 	endDate := pgtype.Timestamp{}
 	if subscription.EndDate == nil {
@@ -121,60 +453,225 @@ func (s *Subscriptions) Create(context context.Context, subscription domain.Subs
 		endDate.Time = *subscription.EndDate
 	}
 
-	if _, err := s.pool.Query(context, query, subscription.ID, subscription.ServiceName, subscription.Price, subscription.UserID, subscription.StartDate, endDate); err != nil {
-		return err
-	}
+	mutation := fmt.Sprintf("INSERT INTO %s (id, tenant_id, service_name, price, currency, billing_period, user_id, start_date, end_date) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING *", s.tableName)
+	args := []any{subscription.ID, tenantID, subscription.ServiceName, subscription.Price, subscription.Currency, subscription.BillingPeriod, subscription.UserID, subscription.StartDate, endDate}
+
+	query, args := s.audited(context, domain.AuditActionCreate, mutation, args, "*")
 
-	return nil
+	return query, args, nil
 }
 
-func (s *Subscriptions) UpdateByID(context context.Context, id uuid.UUID, parameters repository.UpdateParameters) error {
-	query := fmt.Sprintf("UPDATE %s SET ", s.tableName)
+func (s *Subscriptions) updateQuery(context context.Context, id uuid.UUID, versions []int64, parameters repository.UpdateParameters) (string, []any, error) {
+	set := ""
 	args := []any{}
 	idx := 1
 
+	if parameters.ServiceName != nil {
+		set += fmt.Sprintf("service_name = $%d, ", idx)
+		args = append(args, *parameters.ServiceName)
+		idx++
+	}
 	if parameters.Price != nil {
-		query += fmt.Sprintf("price = $%d, ", idx)
+		set += fmt.Sprintf("price = $%d, ", idx)
 		args = append(args, *parameters.Price)
 		idx++
 	}
+	if parameters.Currency != nil {
+		set += fmt.Sprintf("currency = $%d, ", idx)
+		args = append(args, *parameters.Currency)
+		idx++
+	}
+	if parameters.BillingPeriod != nil {
+		set += fmt.Sprintf("billing_period = $%d, ", idx)
+		args = append(args, *parameters.BillingPeriod)
+		idx++
+	}
+	if parameters.UserID != nil {
+		set += fmt.Sprintf("user_id = $%d, ", idx)
+		args = append(args, *parameters.UserID)
+		idx++
+	}
+	if parameters.StartDate != nil {
+		set += fmt.Sprintf("start_date = $%d, ", idx)
+		args = append(args, *parameters.StartDate)
+		idx++
+	}
 	if parameters.EndDate != nil {
-		query += fmt.Sprintf("end_date = $%d, ", idx)
+		set += fmt.Sprintf("end_date = $%d, ", idx)
 		args = append(args, *parameters.EndDate)
 		idx++
 	}
+	if parameters.ClearEndDate {
+		set += "end_date = NULL, "
+	}
 
-	if len(args) == 0 {
-		return domain.ErrNoUpdateParameters
+	if len(args) == 0 && !parameters.ClearEndDate {
+		return "", nil, domain.ErrNoUpdateParameters
 	}
 
-	query = strings.TrimSuffix(query, ", ")
-	query += fmt.Sprintf(" WHERE id = $%d", idx)
+	condition := fmt.Sprintf("id = $%d AND deleted_at IS NULL", idx)
 	args = append(args, id)
+	idx++
 
-	commandTag, err := s.pool.Exec(context, query, args...)
-	if err != nil {
-		return err
+	if len(versions) != 0 {
+		condition += fmt.Sprintf(" AND version = ANY($%d)", idx)
+		args = append(args, versions)
 	}
 
-	if commandTag.RowsAffected() == 0 {
-		return domain.ErrSubscriptionNotFound
+	tenant, args := tenantCondition(context, args)
+
+	query, args := s.auditedUpdate(context, domain.AuditActionUpdate, strings.TrimSuffix(set, ", "), condition+" AND "+tenant, args)
+
+	return query, args, nil
+}
+
+func (s *Subscriptions) deleteQuery(context context.Context, id uuid.UUID, versions []int64) (string, []any) {
+	condition := "id = $1 AND deleted_at IS NULL"
+	args := []any{id}
+
+	if len(versions) != 0 {
+		condition += " AND version = ANY($2)"
+		args = append(args, versions)
 	}
 
-	return nil
+	tenant, args := tenantCondition(context, args)
+
+	return s.auditedUpdate(context, domain.AuditActionDelete, "deleted_at = now()", condition+" AND "+tenant, args)
 }
 
-func (s *Subscriptions) DeleteByID(context context.Context, id uuid.UUID) error {
-	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", s.tableName)
+// auditedUpdate builds a statement that changes the subscriptions matching
+// condition and records their state before and after the change in the audit
+// table. The rows are locked before they are changed, so concurrent writers
+// cannot slip in between the two states.
+func (s *Subscriptions) auditedUpdate(context context.Context, action domain.AuditAction, set, condition string, args []any) (string, []any) {
+	args = append(args, action, domain.ActorFromContext(context), domain.RequestIDFromContext(context))
+	idx := len(args) - 2
+
+	query := fmt.Sprintf(`WITH before AS (SELECT * FROM %[1]s WHERE %[3]s FOR UPDATE),
+		changed AS (UPDATE %[1]s AS t SET %[4]s, version = t.version + 1 FROM before WHERE t.id = before.id RETURNING t.*),
+		audit AS (INSERT INTO %[2]s (subscription_id, tenant_id, action, actor, request_id, before, after)
+			SELECT changed.id, changed.tenant_id, $%[5]d::VARCHAR, $%[6]d::VARCHAR, $%[7]d::VARCHAR, to_jsonb(before), to_jsonb(changed)
+			FROM changed JOIN before ON before.id = changed.id)
+		SELECT * FROM changed`, s.tableName, s.auditTableName, condition, set, idx, idx+1, idx+2)
+
+	return query, args
+}
+
+// audited wraps an INSERT or DELETE statement that returns the changed rows
+// into a statement that also records them in the audit table, and selects
+// result from the changed rows.
+func (s *Subscriptions) audited(context context.Context, action domain.AuditAction, mutation string, args []any, result string) (string, []any) {
+	args = append(args, action, domain.ActorFromContext(context), domain.RequestIDFromContext(context))
+	idx := len(args) - 2
+
+	states := "NULL, to_jsonb(changed)"
+	if action == domain.AuditActionPurge {
+		states = "to_jsonb(changed), NULL"
+	}
 
-	commandTag, err := s.pool.Exec(context, query, id)
+	query := fmt.Sprintf(`WITH changed AS (%[2]s),
+		audit AS (INSERT INTO %[1]s (subscription_id, tenant_id, action, actor, request_id, before, after)
+			SELECT changed.id, changed.tenant_id, $%[3]d::VARCHAR, $%[4]d::VARCHAR, $%[5]d::VARCHAR, %[6]s FROM changed)
+		SELECT %[7]s FROM changed`, s.auditTableName, mutation, idx, idx+1, idx+2, states, result)
+
+	return query, args
+}
+
+// missingError tells apart a conditional write that missed because the
+// subscription does not exist from one that missed because of its version.
+func (s *Subscriptions) missingError(context context.Context, tx pgx.Tx, id uuid.UUID) error {
+	tenant, args := tenantCondition(context, []any{id})
+
+	query := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE id = $1 AND %s AND deleted_at IS NULL)", s.tableName, tenant)
+
+	rows, err := tx.Query(context, query, args...)
 	if err != nil {
 		return err
 	}
 
-	if commandTag.RowsAffected() == 0 {
-		return domain.ErrSubscriptionNotFound
+	exists, err := pgx.CollectExactlyOneRow(rows, pgx.RowTo[bool])
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return domain.ErrVersionMismatch
+	}
+
+	return domain.ErrSubscriptionNotFound
+}
+
+var sortColumnTypes = map[repository.SortField]string{
+	repository.SortByStartDate:   "DATE",
+	repository.SortByPrice:       "BIGINT",
+	repository.SortByServiceName: "TEXT",
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func listFilterConditions(filter repository.ListFilter, args []any) (string, []any) {
+	var query string
+
+	if !filter.IncludeDeleted {
+		query += " AND deleted_at IS NULL"
+	}
+
+	if filter.UserID != nil {
+		args = append(args, *filter.UserID)
+		query += fmt.Sprintf(" AND user_id = $%d", len(args))
+	}
+	if filter.ServiceName != nil {
+		args = append(args, *filter.ServiceName)
+		query += fmt.Sprintf(" AND service_name = $%d", len(args))
+	}
+	if filter.ServiceNamePrefix != nil {
+		args = append(args, likeEscaper.Replace(*filter.ServiceNamePrefix)+"%")
+		query += fmt.Sprintf(" AND service_name LIKE $%d", len(args))
+	}
+	if filter.MinPrice != nil {
+		args = append(args, *filter.MinPrice)
+		query += fmt.Sprintf(" AND price >= $%d", len(args))
+	}
+	if filter.MaxPrice != nil {
+		args = append(args, *filter.MaxPrice)
+		query += fmt.Sprintf(" AND price <= $%d", len(args))
+	}
+	if filter.ActiveOn != nil {
+		args = append(args, *filter.ActiveOn)
+		query += fmt.Sprintf(" AND start_date <= $%[1]d AND (end_date IS NULL OR end_date >= $%[1]d)", len(args))
+	}
+	if filter.Active != nil {
+		condition := "start_date <= CURRENT_DATE AND (end_date IS NULL OR end_date >= date_trunc('month', CURRENT_DATE))"
+		if *filter.Active {
+			query += " AND " + condition
+		} else {
+			query += " AND NOT (" + condition + ")"
+		}
+	}
+	if filter.ActiveFrom != nil {
+		args = append(args, *filter.ActiveFrom)
+		query += fmt.Sprintf(" AND (end_date IS NULL OR end_date >= $%d)", len(args))
+	}
+	if filter.ActiveTo != nil {
+		args = append(args, *filter.ActiveTo)
+		query += fmt.Sprintf(" AND start_date <= $%d", len(args))
+	}
+	if filter.StartDateFrom != nil {
+		args = append(args, *filter.StartDateFrom)
+		query += fmt.Sprintf(" AND start_date >= $%d", len(args))
+	}
+	if filter.StartDateTo != nil {
+		args = append(args, *filter.StartDateTo)
+		query += fmt.Sprintf(" AND start_date <= $%d", len(args))
+	}
+	if filter.EndDateFrom != nil {
+		args = append(args, *filter.EndDateFrom)
+		query += fmt.Sprintf(" AND end_date >= $%d", len(args))
+	}
+	if filter.EndDateTo != nil {
+		args = append(args, *filter.EndDateTo)
+		query += fmt.Sprintf(" AND end_date <= $%d", len(args))
 	}
 
-	return nil
+	return query, args
 }
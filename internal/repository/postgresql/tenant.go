@@ -0,0 +1,105 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/mirrorblade/subscriptions/internal/domain"
+	"github.com/mirrorblade/subscriptions/internal/logging"
+	"go.uber.org/zap"
+)
+
+// inTenant runs fn in a transaction bound to the tenant of context. The
+// row-level security policies of the tenant tables only let the transaction
+// see and change rows of that tenant, or of every tenant for AllTenants.
+func inTenant(context context.Context, pool *pgxpool.Pool, fn func(tx pgx.Tx) error) error {
+	tenantID, ok := domain.TenantFromContext(context)
+	if !ok {
+		return domain.ErrTenantRequired
+	}
+
+	allTenants := "off"
+	if tenantID == domain.AllTenants {
+		allTenants = "on"
+	}
+
+	logger := logging.FromContext(context).With(zap.String("tenant_id", tenantID))
+
+	tx, err := pool.Begin(context)
+	if err != nil {
+		logger.Error("begin transaction", zap.Error(err))
+
+		return err
+	}
+	defer tx.Rollback(context)
+
+	if _, err := tx.Exec(context, "SELECT set_config('app.tenant_id', $1, true), set_config('app.all_tenants', $2, true)", tenantID, allTenants); err != nil {
+		logger.Error("bind transaction to tenant", zap.Error(err))
+
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		logQueryError(logger, err)
+
+		return err
+	}
+
+	if err := tx.Commit(context); err != nil {
+		logger.Error("commit transaction", zap.Error(err))
+
+		return err
+	}
+
+	return nil
+}
+
+// expectedErrors are outcomes of queries that the callers handle, rather
+// than failures of the database.
+var expectedErrors = []error{
+	pgx.ErrNoRows,
+	context.Canceled,
+	domain.ErrSubscriptionNotFound,
+	domain.ErrSubscriptionActive,
+	domain.ErrVersionMismatch,
+	domain.ErrTenantRequired,
+}
+
+// logQueryError logs err as a failure unless it is expected.
+func logQueryError(logger *zap.Logger, err error) {
+	for _, expected := range expectedErrors {
+		if errors.Is(err, expected) {
+			logger.Debug("query", zap.Error(err))
+
+			return
+		}
+	}
+
+	fields := []zap.Field{zap.Error(err)}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		fields = append(fields, zap.String("code", pgErr.Code), zap.String("constraint", pgErr.ConstraintName))
+	}
+
+	logger.Error("query", fields...)
+}
+
+// tenantCondition appends the tenant of context to args and returns the
+// condition that restricts a query to it. Queries are restricted even though
+// the policies would do it, so that they stay correct for roles that bypass
+// row-level security.
+func tenantCondition(context context.Context, args []any) (string, []any) {
+	tenantID, _ := domain.TenantFromContext(context)
+	if tenantID == domain.AllTenants {
+		return "TRUE", args
+	}
+
+	args = append(args, tenantID)
+
+	return fmt.Sprintf("tenant_id = $%d", len(args)), args
+}
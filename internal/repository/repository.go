@@ -12,28 +12,121 @@ type GetSumParameters struct {
 	ServiceName *string
 	FromDate    *time.Time
 	ToDate      *time.Time
+	Period      domain.BillingPeriod
+	Currency    domain.Currency
+}
+
+type SortField string
+
+const (
+	SortByStartDate   SortField = "start_date"
+	SortByPrice       SortField = "price"
+	SortByServiceName SortField = "service_name"
+)
+
+func (f SortField) Valid() bool {
+	switch f {
+	case SortByStartDate, SortByPrice, SortByServiceName:
+		return true
+	}
+
+	return false
+}
+
+type ListFilter struct {
+	IncludeDeleted    bool
+	UserID            *uuid.UUID
+	ServiceName       *string
+	ServiceNamePrefix *string
+	MinPrice          *int64
+	MaxPrice          *int64
+	Active            *bool
+	ActiveOn          *time.Time
+	ActiveFrom        *time.Time
+	ActiveTo          *time.Time
+	StartDateFrom     *time.Time
+	StartDateTo       *time.Time
+	EndDateFrom       *time.Time
+	EndDateTo         *time.Time
+}
+
+type ListParameters struct {
+	Filter     ListFilter
+	SortBy     SortField
+	Descending bool
+	Limit      int
+	After      *Cursor
+}
+
+type SearchParameters struct {
+	Filter     ListFilter
+	SortBy     SortField
+	Descending bool
+	Limit      int
+	Offset     int
 }
 
 type UpdateParameters struct {
-	Price   *int64
-	EndDate *time.Time
+	ServiceName   *string
+	Price         *int64
+	Currency      *domain.Currency
+	BillingPeriod *domain.BillingPeriod
+	UserID        *uuid.UUID
+	StartDate     *time.Time
+	EndDate       *time.Time
+	ClearEndDate  bool
 }
 
 type Subscriptions interface {
-	GetByID(context context.Context, id uuid.UUID) (domain.Subscription, error)
-	GetListByUserID(context context.Context, userID uuid.UUID) ([]domain.Subscription, error)
-	GetPriceSumByUserID(context context.Context, userID uuid.UUID, parameters GetSumParameters) (int64, error)
-	Create(context context.Context, subscription domain.Subscription) error
-	UpdateByID(context context.Context, id uuid.UUID, parameters UpdateParameters) error
-	DeleteByID(context context.Context, id uuid.UUID) error
+	GetByID(context context.Context, id uuid.UUID, includeDeleted bool) (domain.Subscription, error)
+	GetList(context context.Context, parameters ListParameters) ([]domain.Subscription, error)
+	ForEach(context context.Context, parameters ListParameters, fn func(domain.Subscription) error) error
+	Search(context context.Context, parameters SearchParameters) ([]domain.Subscription, int64, error)
+	GetListInPeriodByUserID(context context.Context, userID uuid.UUID, parameters GetSumParameters) ([]domain.Subscription, error)
+	Create(context context.Context, subscription domain.Subscription) (domain.Subscription, error)
+	UpdateByID(context context.Context, id uuid.UUID, versions []int64, parameters UpdateParameters) (domain.Subscription, error)
+	DeleteByID(context context.Context, id uuid.UUID, versions []int64) error
+	RestoreByID(context context.Context, id uuid.UUID) (domain.Subscription, error)
+	PurgeDeleted(context context.Context, before time.Time) (int64, error)
+	Batch(context context.Context, operations []BatchOperation) ([]domain.Subscription, error)
+}
+
+type ExchangeRates interface {
+	GetRate(context context.Context, from, to domain.Currency) (float64, error)
+}
+
+type Audit interface {
+	GetListBySubscriptionID(context context.Context, subscriptionID uuid.UUID) ([]domain.AuditRecord, error)
+}
+
+type IdempotencyKeys interface {
+	Reserve(context context.Context, key, requestHash string, expiresAt time.Time) (domain.IdempotencyKey, bool, error)
+	Complete(context context.Context, key string, response domain.IdempotentResponse) error
+	Release(context context.Context, key string) error
+	PurgeExpired(context context.Context, before time.Time) (int64, error)
+}
+
+type APIKeys interface {
+	Create(context context.Context, key domain.APIKey) (domain.APIKey, error)
+	GetByHash(context context.Context, hash string) (domain.APIKey, error)
+	GetList(context context.Context) ([]domain.APIKey, error)
+	Revoke(context context.Context, id uuid.UUID) error
 }
 
 type Respository struct {
-	Subscriptions Subscriptions
+	Subscriptions   Subscriptions
+	ExchangeRates   ExchangeRates
+	Audit           Audit
+	IdempotencyKeys IdempotencyKeys
+	APIKeys         APIKeys
 }
 
-func New(subscriptions Subscriptions) *Respository {
+func New(subscriptions Subscriptions, exchangeRates ExchangeRates, audit Audit, idempotencyKeys IdempotencyKeys, apiKeys APIKeys) *Respository {
 	return &Respository{
-		Subscriptions: subscriptions,
+		Subscriptions:   subscriptions,
+		ExchangeRates:   exchangeRates,
+		Audit:           audit,
+		IdempotencyKeys: idempotencyKeys,
+		APIKeys:         apiKeys,
 	}
 }
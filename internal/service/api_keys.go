@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mirrorblade/subscriptions/internal/domain"
+	"github.com/mirrorblade/subscriptions/internal/repository"
+	"github.com/mirrorblade/subscriptions/internal/validation"
+)
+
+const (
+	apiKeyPrefix = "sk_"
+
+	// apiKeyPrefixLength is the length of the part of a key that is stored
+	// in plain text to tell keys apart.
+	apiKeyPrefixLength = len(apiKeyPrefix) + 8
+)
+
+type APIKeysService struct {
+	keys repository.APIKeys
+}
+
+func NewAPIKeysService(keys repository.APIKeys) *APIKeysService {
+	return &APIKeysService{
+		keys: keys,
+	}
+}
+
+// Create mints a key of tenantID with scopes and roles that expires after
+// ttl, or never if ttl is zero. The returned secret is not stored and can not
+// be shown again.
+func (s *APIKeysService) Create(context context.Context, name, tenantID string, scopes, roles []string, ttl time.Duration) (domain.APIKey, string, error) {
+	if err := authorizeAdmin(context); err != nil {
+		return domain.APIKey{}, "", err
+	}
+
+	name = strings.TrimSpace(name)
+	scopes = slices.Compact(slices.Sorted(slices.Values(scopes)))
+	roles = slices.Compact(slices.Sorted(slices.Values(roles)))
+
+	v := new(validation.Validator)
+	v.Check(name != "" && len(name) <= 255, "name", domain.ErrInvalidValue)
+	v.Check(domain.ValidTenantID(tenantID), "tenant_id", domain.ErrInvalidTenant)
+	v.Check(len(scopes) != 0, "scopes", domain.ErrInvalidScope)
+	v.Check(ttl >= 0, "ttl", domain.ErrInvalidValue)
+
+	for _, scope := range scopes {
+		v.Check(slices.Contains(domain.Scopes, scope), "scopes", domain.ErrInvalidScope)
+	}
+
+	for _, role := range roles {
+		v.Check(role != "" && len(role) <= 255, "roles", domain.ErrInvalidValue)
+	}
+
+	if err := v.Err(); err != nil {
+		return domain.APIKey{}, "", err
+	}
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return domain.APIKey{}, "", err
+	}
+
+	secret := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(random)
+
+	key := domain.APIKey{
+		ID:       uuid.New(),
+		Name:     name,
+		Prefix:   secret[:apiKeyPrefixLength],
+		Hash:     hashAPIKey(secret),
+		TenantID: tenantID,
+		Scopes:   scopes,
+		Roles:    roles,
+	}
+
+	if ttl != 0 {
+		expiresAt := time.Now().Add(ttl)
+		key.ExpiresAt = &expiresAt
+	}
+
+	key, err := s.keys.Create(context, key)
+	if err != nil {
+		return domain.APIKey{}, "", err
+	}
+
+	return key, secret, nil
+}
+
+func (s *APIKeysService) GetList(context context.Context) ([]domain.APIKey, error) {
+	if err := authorizeAdmin(context); err != nil {
+		return []domain.APIKey{}, err
+	}
+
+	return s.keys.GetList(context)
+}
+
+func (s *APIKeysService) Revoke(context context.Context, id uuid.UUID) error {
+	if err := authorizeAdmin(context); err != nil {
+		return err
+	}
+
+	return s.keys.Revoke(context, id)
+}
+
+// Authenticate returns the principal of the active key secret.
+func (s *APIKeysService) Authenticate(context context.Context, secret string) (domain.Principal, error) {
+	if !strings.HasPrefix(secret, apiKeyPrefix) {
+		return domain.Principal{}, domain.ErrInvalidAPIKey
+	}
+
+	key, err := s.keys.GetByHash(context, hashAPIKey(secret))
+	if err != nil {
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			return domain.Principal{}, domain.ErrInvalidAPIKey
+		}
+
+		return domain.Principal{}, err
+	}
+
+	if !key.Active(time.Now()) {
+		return domain.Principal{}, domain.ErrInvalidAPIKey
+	}
+
+	return domain.Principal{
+		APIKeyID: key.ID,
+		TenantID: key.TenantID,
+		Scopes:   key.Scopes,
+		Roles:    key.Roles,
+	}, nil
+}
+
+// hashAPIKey hashes secret for lookups. Secrets are random, so a fast hash
+// is enough to keep them from being recovered.
+func hashAPIKey(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/mirrorblade/subscriptions/internal/domain"
+	"github.com/mirrorblade/subscriptions/internal/logging"
+	"github.com/mirrorblade/subscriptions/internal/repository"
+	"go.uber.org/zap"
+)
+
+// authorize checks that the caller may access the subscriptions of userID:
+// users may access their own subscriptions, and those the policy allows to
+// access every user those of everyone.
+func (s *SubscriptionsService) authorize(context context.Context, userID uuid.UUID) error {
+	principal, ok := domain.PrincipalFromContext(context)
+	if !ok {
+		return domain.ErrUnauthenticated
+	}
+
+	if s.policy.AllowsAllUsers(principal) || principal.UserID == userID {
+		return nil
+	}
+
+	logging.FromContext(context).Info("access to subscriptions of another user denied",
+		zap.Stringer("user_id", principal.UserID), zap.Stringer("owner_id", userID))
+
+	return domain.ErrForbidden
+}
+
+func authorizeAdmin(context context.Context) error {
+	principal, ok := domain.PrincipalFromContext(context)
+	if !ok {
+		return domain.ErrUnauthenticated
+	}
+
+	if !principal.Admin() {
+		return domain.ErrForbidden
+	}
+
+	return nil
+}
+
+// authorizeIncludeDeleted checks that the caller may see deleted
+// subscriptions if includeDeleted is set, which only admins may.
+func authorizeIncludeDeleted(context context.Context, includeDeleted bool) error {
+	if !includeDeleted {
+		return nil
+	}
+
+	principal, ok := domain.PrincipalFromContext(context)
+	if !ok {
+		return domain.ErrUnauthenticated
+	}
+
+	if !principal.Admin() {
+		return &domain.FieldError{Field: "include_deleted", Err: domain.ErrForbidden}
+	}
+
+	return nil
+}
+
+// authorizeFilter restricts filter to the subscriptions of the caller unless
+// the policy allows it to access every user. Filtering by another user and
+// including deleted subscriptions without being an admin are forbidden.
+func (s *SubscriptionsService) authorizeFilter(context context.Context, filter *repository.ListFilter) error {
+	principal, ok := domain.PrincipalFromContext(context)
+	if !ok {
+		return domain.ErrUnauthenticated
+	}
+
+	if err := authorizeIncludeDeleted(context, filter.IncludeDeleted); err != nil {
+		return err
+	}
+
+	if s.policy.AllowsAllUsers(principal) {
+		return nil
+	}
+
+	if filter.UserID == nil {
+		filter.UserID = &principal.UserID
+
+		return nil
+	}
+
+	if *filter.UserID != principal.UserID {
+		logging.FromContext(context).Info("access to subscriptions of another user denied",
+			zap.Stringer("user_id", principal.UserID), zap.Stringer("owner_id", *filter.UserID))
+
+		return domain.ErrForbidden
+	}
+
+	return nil
+}
+
+// getAuthorized returns the subscription with id if the caller may access it.
+func (s *SubscriptionsService) getAuthorized(context context.Context, id uuid.UUID, includeDeleted bool) (domain.Subscription, error) {
+	subscription, err := s.subscriptions.GetByID(context, id, includeDeleted)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+
+	if err := s.authorize(context, subscription.UserID); err != nil {
+		return domain.Subscription{}, err
+	}
+
+	return subscription, nil
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/mirrorblade/subscriptions/internal/domain"
+	"github.com/mirrorblade/subscriptions/internal/repository"
+)
+
+type IdempotencyService struct {
+	keys repository.IdempotencyKeys
+
+	ttl time.Duration
+}
+
+func NewIdempotencyService(keys repository.IdempotencyKeys, ttl time.Duration) *IdempotencyService {
+	return &IdempotencyService{
+		keys: keys,
+		ttl:  ttl,
+	}
+}
+
+// Begin reserves key for the request with requestHash. It returns the stored
+// response when the request was already processed, or nil when the caller
+// should process it and then Complete or Release the key.
+func (s *IdempotencyService) Begin(context context.Context, key, requestHash string) (*domain.IdempotentResponse, error) {
+	idempotencyKey, reserved, err := s.keys.Reserve(context, scopedKey(context, key), requestHash, time.Now().Add(s.ttl))
+	if err != nil {
+		return nil, err
+	}
+
+	if reserved {
+		return nil, nil
+	}
+
+	if idempotencyKey.RequestHash != requestHash {
+		return nil, domain.ErrIdempotencyKeyReused
+	}
+
+	if idempotencyKey.Response == nil {
+		return nil, domain.ErrIdempotencyKeyInProgress
+	}
+
+	return idempotencyKey.Response, nil
+}
+
+func (s *IdempotencyService) Complete(context context.Context, key string, response domain.IdempotentResponse) error {
+	return s.keys.Complete(context, scopedKey(context, key), response)
+}
+
+func (s *IdempotencyService) Release(context context.Context, key string) error {
+	return s.keys.Release(context, scopedKey(context, key))
+}
+
+func (s *IdempotencyService) PurgeExpired(context context.Context) (int64, error) {
+	return s.keys.PurgeExpired(context, time.Now())
+}
+
+// scopedKey makes the keys of different callers and tenants distinct, so
+// that a caller can not replay the response to another caller's request.
+func scopedKey(context context.Context, key string) string {
+	principal, _ := domain.PrincipalFromContext(context)
+	tenantID, _ := domain.TenantFromContext(context)
+
+	hash := sha256.Sum256([]byte(tenantID + ":" + principal.UserID.String() + ":" + principal.APIKeyID.String() + ":" + key))
+
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/mirrorblade/subscriptions/internal/domain"
+	"github.com/mirrorblade/subscriptions/internal/importer"
+	"github.com/mirrorblade/subscriptions/internal/validation"
+)
+
+type ImportService struct {
+	subscriptions Subscriptions
+
+	sanitizer *bluemonday.Policy
+
+	batchSize int
+}
+
+func NewImportService(subscriptions Subscriptions, sanitizer *bluemonday.Policy, batchSize int) *ImportService {
+	if batchSize <= 0 || batchSize > maxBatchSize {
+		batchSize = maxBatchSize
+	}
+
+	return &ImportService{
+		subscriptions: subscriptions,
+		sanitizer:     sanitizer,
+		batchSize:     batchSize,
+	}
+}
+
+// Import reads subscriptions from r and creates the valid ones in batches.
+// Rows that can not be read or are not valid are reported and skipped.
+func (s *ImportService) Import(context context.Context, r io.Reader, format importer.Format, dryRun bool) (domain.ImportReport, error) {
+	report := domain.ImportReport{
+		DryRun: dryRun,
+		Errors: []domain.ImportRowError{},
+	}
+
+	reader, err := importer.NewReader(r, format)
+	if err != nil {
+		return domain.ImportReport{}, err
+	}
+
+	rows := make([]int, 0, s.batchSize)
+	subscriptions := make([]domain.Subscription, 0, s.batchSize)
+
+	flush := func() error {
+		if len(subscriptions) == 0 {
+			return nil
+		}
+
+		results, err := s.subscriptions.CreateBatch(context, subscriptions, dryRun)
+		if err != nil {
+			return err
+		}
+
+		for i, result := range results {
+			if result.Err != nil {
+				report.Errors = append(report.Errors, domain.ImportRowError{Row: rows[i], Message: result.Err.Error()})
+
+				continue
+			}
+
+			report.Valid++
+			if !dryRun {
+				report.Imported++
+			}
+		}
+
+		rows = rows[:0]
+		subscriptions = subscriptions[:0]
+
+		return nil
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			var rowErr *importer.RowError
+			if !errors.As(err, &rowErr) {
+				return domain.ImportReport{}, err
+			}
+
+			report.Total++
+			report.Errors = append(report.Errors, domain.ImportRowError{Row: rowErr.Row, Message: rowErr.Err.Error()})
+
+			continue
+		}
+
+		report.Total++
+
+		subscription, err := s.subscription(record)
+		if err != nil {
+			report.Errors = append(report.Errors, domain.ImportRowError{Row: record.Row, Message: err.Error()})
+
+			continue
+		}
+
+		rows = append(rows, record.Row)
+		subscriptions = append(subscriptions, subscription)
+
+		if len(subscriptions) == s.batchSize {
+			if err := flush(); err != nil {
+				return domain.ImportReport{}, err
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return domain.ImportReport{}, err
+	}
+
+	slices.SortStableFunc(report.Errors, func(a, b domain.ImportRowError) int {
+		return a.Row - b.Row
+	})
+
+	return report, nil
+}
+
+// subscription converts record into a subscription the same way the REST
+// handler converts a request body.
+func (s *ImportService) subscription(record importer.Record) (domain.Subscription, error) {
+	return validation.ParseSubscription(validation.SubscriptionInput{
+		ServiceName:   s.sanitizer.Sanitize(record.ServiceName),
+		Price:         record.Price,
+		Currency:      record.Currency,
+		BillingPeriod: record.BillingPeriod,
+		UserID:        record.UserID,
+		StartDate:     record.StartDate,
+		EndDate:       record.EndDate,
+	})
+}
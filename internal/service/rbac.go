@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mirrorblade/subscriptions/internal/domain"
+	"github.com/mirrorblade/subscriptions/internal/logging"
+	"github.com/mirrorblade/subscriptions/internal/rbac"
+	"github.com/mirrorblade/subscriptions/internal/repository"
+	"go.uber.org/zap"
+)
+
+// RBACSubscriptions checks that the roles of the caller allow an operation
+// before passing it on to subscriptions.
+type RBACSubscriptions struct {
+	subscriptions Subscriptions
+
+	policy *rbac.Policy
+}
+
+func NewRBACSubscriptions(subscriptions Subscriptions, policy *rbac.Policy) *RBACSubscriptions {
+	return &RBACSubscriptions{
+		subscriptions: subscriptions,
+		policy:        policy,
+	}
+}
+
+func (s *RBACSubscriptions) GetByID(context context.Context, id uuid.UUID, includeDeleted bool) (domain.Subscription, error) {
+	if err := s.allow(context, rbac.OperationRead); err != nil {
+		return domain.Subscription{}, err
+	}
+
+	return s.subscriptions.GetByID(context, id, includeDeleted)
+}
+
+func (s *RBACSubscriptions) GetList(context context.Context, parameters repository.ListParameters) (domain.SubscriptionList, error) {
+	if err := s.allow(context, rbac.OperationRead); err != nil {
+		return domain.SubscriptionList{}, err
+	}
+
+	return s.subscriptions.GetList(context, parameters)
+}
+
+func (s *RBACSubscriptions) Export(context context.Context, parameters repository.ListParameters, fn func(domain.Subscription) error) error {
+	if err := s.allow(context, rbac.OperationRead); err != nil {
+		return err
+	}
+
+	return s.subscriptions.Export(context, parameters, fn)
+}
+
+func (s *RBACSubscriptions) Search(context context.Context, parameters repository.SearchParameters) (domain.SubscriptionSearchResult, error) {
+	if err := s.allow(context, rbac.OperationRead); err != nil {
+		return domain.SubscriptionSearchResult{}, err
+	}
+
+	return s.subscriptions.Search(context, parameters)
+}
+
+func (s *RBACSubscriptions) GetPriceSumByUserID(context context.Context, userID uuid.UUID, parameters repository.GetSumParameters) (domain.PriceSum, error) {
+	if err := s.allow(context, rbac.OperationAggregate); err != nil {
+		return domain.PriceSum{}, err
+	}
+
+	return s.subscriptions.GetPriceSumByUserID(context, userID, parameters)
+}
+
+func (s *RBACSubscriptions) Create(context context.Context, subscription domain.Subscription) (domain.Subscription, error) {
+	if err := s.allow(context, rbac.OperationCreate); err != nil {
+		return domain.Subscription{}, err
+	}
+
+	return s.subscriptions.Create(context, subscription)
+}
+
+func (s *RBACSubscriptions) UpdateByID(context context.Context, id uuid.UUID, versions []int64, parameters repository.UpdateParameters) (domain.Subscription, error) {
+	if err := s.allow(context, rbac.OperationUpdate); err != nil {
+		return domain.Subscription{}, err
+	}
+
+	return s.subscriptions.UpdateByID(context, id, versions, parameters)
+}
+
+func (s *RBACSubscriptions) DeleteByID(context context.Context, id uuid.UUID, versions []int64) error {
+	if err := s.allow(context, rbac.OperationDelete); err != nil {
+		return err
+	}
+
+	return s.subscriptions.DeleteByID(context, id, versions)
+}
+
+func (s *RBACSubscriptions) RestoreByID(context context.Context, id uuid.UUID) (domain.Subscription, error) {
+	if err := s.allow(context, rbac.OperationRestore); err != nil {
+		return domain.Subscription{}, err
+	}
+
+	return s.subscriptions.RestoreByID(context, id)
+}
+
+func (s *RBACSubscriptions) GetHistoryByID(context context.Context, id uuid.UUID) ([]domain.AuditRecord, error) {
+	if err := s.allow(context, rbac.OperationRead); err != nil {
+		return []domain.AuditRecord{}, err
+	}
+
+	return s.subscriptions.GetHistoryByID(context, id)
+}
+
+func (s *RBACSubscriptions) PurgeDeleted(context context.Context, retention time.Duration) (int64, error) {
+	if err := s.allow(context, rbac.OperationDelete); err != nil {
+		return 0, err
+	}
+
+	return s.subscriptions.PurgeDeleted(context, retention)
+}
+
+// Batch requires every operation the batch contains to be allowed, so that
+// a batch is never applied partly because of the roles of the caller.
+func (s *RBACSubscriptions) Batch(context context.Context, operations []repository.BatchOperation, atomic bool) ([]repository.BatchResult, error) {
+	for _, operation := range operations {
+		var err error
+
+		switch operation.Type {
+		case repository.BatchOperationCreate:
+			err = s.allow(context, rbac.OperationCreate)
+		case repository.BatchOperationUpdate:
+			err = s.allow(context, rbac.OperationUpdate)
+		case repository.BatchOperationDelete:
+			err = s.allow(context, rbac.OperationDelete)
+		}
+
+		if err != nil {
+			return []repository.BatchResult{}, err
+		}
+	}
+
+	return s.subscriptions.Batch(context, operations, atomic)
+}
+
+func (s *RBACSubscriptions) CreateBatch(context context.Context, subscriptions []domain.Subscription, dryRun bool) ([]repository.BatchResult, error) {
+	if err := s.allow(context, rbac.OperationCreate); err != nil {
+		return []repository.BatchResult{}, err
+	}
+
+	return s.subscriptions.CreateBatch(context, subscriptions, dryRun)
+}
+
+func (s *RBACSubscriptions) allow(context context.Context, operation rbac.Operation) error {
+	principal, ok := domain.PrincipalFromContext(context)
+	if !ok {
+		return domain.ErrUnauthenticated
+	}
+
+	if !s.policy.Allows(principal, operation) {
+		logging.FromContext(context).Info("operation denied",
+			zap.String("operation", string(operation)), zap.Strings("roles", principal.Roles))
+
+		return domain.ErrOperationDenied
+	}
+
+	return nil
+}
@@ -2,27 +2,61 @@ package service
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/mirrorblade/subscriptions/internal/domain"
+	"github.com/mirrorblade/subscriptions/internal/importer"
 	"github.com/mirrorblade/subscriptions/internal/repository"
 )
 
 type Subscriptions interface {
-	GetByID(context context.Context, id uuid.UUID) (domain.Subscription, error)
-	GetListByUserID(context context.Context, userID uuid.UUID) ([]domain.Subscription, error)
-	GetPriceSumByUserID(context context.Context, userID uuid.UUID, parameters repository.GetSumParameters) (int64, error)
-	Create(context context.Context, subscription domain.Subscription) error
-	UpdateByID(context context.Context, id uuid.UUID, parameters repository.UpdateParameters) error
-	DeleteByID(context context.Context, id uuid.UUID) error
+	GetByID(context context.Context, id uuid.UUID, includeDeleted bool) (domain.Subscription, error)
+	GetList(context context.Context, parameters repository.ListParameters) (domain.SubscriptionList, error)
+	Export(context context.Context, parameters repository.ListParameters, fn func(domain.Subscription) error) error
+	Search(context context.Context, parameters repository.SearchParameters) (domain.SubscriptionSearchResult, error)
+	GetPriceSumByUserID(context context.Context, userID uuid.UUID, parameters repository.GetSumParameters) (domain.PriceSum, error)
+	Create(context context.Context, subscription domain.Subscription) (domain.Subscription, error)
+	UpdateByID(context context.Context, id uuid.UUID, versions []int64, parameters repository.UpdateParameters) (domain.Subscription, error)
+	DeleteByID(context context.Context, id uuid.UUID, versions []int64) error
+	RestoreByID(context context.Context, id uuid.UUID) (domain.Subscription, error)
+	GetHistoryByID(context context.Context, id uuid.UUID) ([]domain.AuditRecord, error)
+	PurgeDeleted(context context.Context, retention time.Duration) (int64, error)
+	Batch(context context.Context, operations []repository.BatchOperation, atomic bool) ([]repository.BatchResult, error)
+	CreateBatch(context context.Context, subscriptions []domain.Subscription, dryRun bool) ([]repository.BatchResult, error)
+}
+
+type Import interface {
+	Import(context context.Context, r io.Reader, format importer.Format, dryRun bool) (domain.ImportReport, error)
+}
+
+type Idempotency interface {
+	Begin(context context.Context, key, requestHash string) (*domain.IdempotentResponse, error)
+	Complete(context context.Context, key string, response domain.IdempotentResponse) error
+	Release(context context.Context, key string) error
+	PurgeExpired(context context.Context) (int64, error)
+}
+
+type APIKeys interface {
+	Create(context context.Context, name, tenantID string, scopes, roles []string, ttl time.Duration) (domain.APIKey, string, error)
+	GetList(context context.Context) ([]domain.APIKey, error)
+	Revoke(context context.Context, id uuid.UUID) error
+	Authenticate(context context.Context, secret string) (domain.Principal, error)
 }
 
 type Service struct {
 	Subscriptions Subscriptions
+	Idempotency   Idempotency
+	Import        Import
+	APIKeys       APIKeys
 }
 
-func New(subscriptions Subscriptions) *Service {
+func New(subscriptions Subscriptions, idempotency Idempotency, importService Import, apiKeys APIKeys) *Service {
 	return &Service{
 		Subscriptions: subscriptions,
+		Idempotency:   idempotency,
+		Import:        importService,
+		APIKeys:       apiKeys,
 	}
 }
@@ -2,52 +2,582 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/mirrorblade/subscriptions/internal/domain"
+	"github.com/mirrorblade/subscriptions/internal/logging"
+	"github.com/mirrorblade/subscriptions/internal/rbac"
 	"github.com/mirrorblade/subscriptions/internal/repository"
+	"github.com/mirrorblade/subscriptions/internal/validation"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+	maxBatchSize     = 1000
 )
 
 type SubscriptionsService struct {
 	subscriptions repository.Subscriptions
+	exchangeRates repository.ExchangeRates
+	audit         repository.Audit
+
+	policy *rbac.Policy
 }
 
-func NewSubscriptionsService(subscriptions Subscriptions) *SubscriptionsService {
+func NewSubscriptionsService(subscriptions repository.Subscriptions, exchangeRates repository.ExchangeRates, audit repository.Audit, policy *rbac.Policy) *SubscriptionsService {
 	return &SubscriptionsService{
 		subscriptions: subscriptions,
+		exchangeRates: exchangeRates,
+		audit:         audit,
+		policy:        policy,
 	}
 }
 
-func (s *SubscriptionsService) GetByID(context context.Context, id uuid.UUID) (domain.Subscription, error) {
-	return s.subscriptions.GetByID(context, id)
+func (s *SubscriptionsService) GetByID(context context.Context, id uuid.UUID, includeDeleted bool) (domain.Subscription, error) {
+	if err := authorizeIncludeDeleted(context, includeDeleted); err != nil {
+		return domain.Subscription{}, err
+	}
+
+	return s.getAuthorized(context, id, includeDeleted)
 }
 
-func (s *SubscriptionsService) GetListByUserID(context context.Context, userID uuid.UUID) ([]domain.Subscription, error) {
-	return s.subscriptions.GetListByUserID(context, userID)
+func (s *SubscriptionsService) GetList(context context.Context, parameters repository.ListParameters) (domain.SubscriptionList, error) {
+	if parameters.SortBy == "" {
+		parameters.SortBy = repository.SortByStartDate
+	}
+
+	if !parameters.SortBy.Valid() {
+		return domain.SubscriptionList{}, &domain.FieldError{Field: "sort_by", Err: domain.ErrInvalidSort}
+	}
+
+	if err := s.authorizeFilter(context, &parameters.Filter); err != nil {
+		return domain.SubscriptionList{}, err
+	}
+
+	if parameters.After != nil && (parameters.After.SortBy != parameters.SortBy || parameters.After.Descending != parameters.Descending) {
+		return domain.SubscriptionList{}, &domain.FieldError{Field: "cursor", Err: domain.ErrInvalidCursor}
+	}
+
+	if parameters.Limit <= 0 {
+		parameters.Limit = defaultListLimit
+	}
+	parameters.Limit = min(parameters.Limit, maxListLimit)
+
+	limit := parameters.Limit
+	parameters.Limit++
+
+	subscriptions, err := s.subscriptions.GetList(context, parameters)
+	if err != nil {
+		return domain.SubscriptionList{}, err
+	}
+
+	list := domain.SubscriptionList{
+		Subscriptions: subscriptions,
+	}
+
+	if len(subscriptions) > limit {
+		list.Subscriptions = subscriptions[:limit]
+		list.NextCursor = repository.NewCursor(subscriptions[limit-1], parameters.SortBy, parameters.Descending).Encode()
+	}
+
+	return list, nil
 }
 
-func (s *SubscriptionsService) GetPriceSumByUserID(context context.Context, userID uuid.UUID, parameters repository.GetSumParameters) (int64, error) {
-	return s.subscriptions.GetPriceSumByUserID(context, userID, parameters)
+// Export calls fn for every subscription matching the filters of parameters
+// in the requested order. Unlike GetList it is not paginated: the limit and
+// cursor of parameters are ignored.
+func (s *SubscriptionsService) Export(context context.Context, parameters repository.ListParameters, fn func(domain.Subscription) error) error {
+	if parameters.SortBy == "" {
+		parameters.SortBy = repository.SortByStartDate
+	}
+
+	if !parameters.SortBy.Valid() {
+		return &domain.FieldError{Field: "sort_by", Err: domain.ErrInvalidSort}
+	}
+
+	if err := s.authorizeFilter(context, &parameters.Filter); err != nil {
+		return err
+	}
+
+	parameters.Limit = 0
+	parameters.After = nil
+
+	return s.subscriptions.ForEach(context, parameters, fn)
 }
 
-func (s *SubscriptionsService) Create(context context.Context, subscription domain.Subscription) error {
-	if subscription.Price < 0 {
-		return domain.ErrInvalidPrice
+func (s *SubscriptionsService) Search(context context.Context, parameters repository.SearchParameters) (domain.SubscriptionSearchResult, error) {
+	if parameters.SortBy == "" {
+		parameters.SortBy = repository.SortByStartDate
 	}
 
-	if subscription.EndDate != nil && (*subscription.EndDate).Before(subscription.StartDate) {
-		return domain.ErrInvalidDate
+	if !parameters.SortBy.Valid() {
+		return domain.SubscriptionSearchResult{}, &domain.FieldError{Field: "sort_by", Err: domain.ErrInvalidSort}
 	}
 
-	subscription.ID = uuid.New()
+	if err := s.authorizeFilter(context, &parameters.Filter); err != nil {
+		return domain.SubscriptionSearchResult{}, err
+	}
+
+	if parameters.Limit <= 0 {
+		parameters.Limit = defaultListLimit
+	}
+	parameters.Limit = min(parameters.Limit, maxListLimit)
+	parameters.Offset = max(parameters.Offset, 0)
+
+	subscriptions, total, err := s.subscriptions.Search(context, parameters)
+	if err != nil {
+		return domain.SubscriptionSearchResult{}, err
+	}
+
+	return domain.SubscriptionSearchResult{
+		Subscriptions: subscriptions,
+		Total:         total,
+	}, nil
+}
+
+// billedSubscription is a subscription with the part of the requested period
+// it is billed for.
+type billedSubscription struct {
+	subscription domain.Subscription
+	from, to     time.Time
+}
+
+func (s *SubscriptionsService) GetPriceSumByUserID(context context.Context, userID uuid.UUID, parameters repository.GetSumParameters) (domain.PriceSum, error) {
+	if err := s.authorize(context, userID); err != nil {
+		return domain.PriceSum{}, err
+	}
+
+	if parameters.Period == "" {
+		parameters.Period = domain.BillingPeriodMonthly
+	}
+
+	if !parameters.Period.Valid() {
+		return domain.PriceSum{}, &domain.FieldError{Field: "period", Err: domain.ErrInvalidBillingPeriod}
+	}
+
+	if parameters.Currency != "" && !parameters.Currency.Valid() {
+		return domain.PriceSum{}, &domain.FieldError{Field: "currency", Err: domain.ErrInvalidCurrency}
+	}
+
+	subscriptions, err := s.subscriptions.GetListInPeriodByUserID(context, userID, parameters)
+	if err != nil {
+		return domain.PriceSum{}, err
+	}
+
+	now := time.Now().UTC()
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	var billed []billedSubscription
+
+	for _, subscription := range subscriptions {
+		from := subscription.StartDate
+		if parameters.FromDate != nil && parameters.FromDate.After(from) {
+			from = *parameters.FromDate
+		}
+
+		// An open-ended subscription is still active, so it is billed up to
+		// the end of the requested window or up to the current month.
+		to := currentMonth
+		if parameters.ToDate != nil {
+			to = *parameters.ToDate
+		}
+		if subscription.EndDate != nil && subscription.EndDate.Before(to) {
+			to = *subscription.EndDate
+		}
+
+		if to.Before(from) {
+			continue
+		}
+
+		billed = append(billed, billedSubscription{subscription: subscription, from: from, to: to})
+	}
+
+	// Only subscriptions billed in the period decide the currency, and a sum
+	// without any is in the default currency.
+	currency := parameters.Currency
+	if currency == "" {
+		currency = domain.DefaultCurrency
+
+		for i, item := range billed {
+			if i == 0 {
+				currency = item.subscription.Currency
+			} else if item.subscription.Currency != currency {
+				return domain.PriceSum{}, &domain.FieldError{Field: "currency", Err: domain.ErrCurrencyRequired}
+			}
+		}
+	}
+
+	rates := map[domain.Currency]float64{}
+
+	sum := domain.PriceSum{
+		Currency:      currency,
+		Period:        parameters.Period,
+		Subscriptions: []domain.SubscriptionCost{},
+	}
+
+	for _, item := range billed {
+		subscription, from, to := item.subscription, item.from, item.to
+
+		rate, ok := rates[subscription.Currency]
+		if !ok {
+			rate, err = s.exchangeRates.GetRate(context, subscription.Currency, currency)
+			if err != nil {
+				return domain.PriceSum{}, err
+			}
+
+			rates[subscription.Currency] = rate
+		}
+
+		charges := subscription.BillingPeriod.Charges(subscription.StartDate, from, to)
+		periodPrice := subscription.BillingPeriod.Normalize(subscription.Price, parameters.Period)
+
+		cost := domain.SubscriptionCost{
+			Subscription: subscription,
+			Charges:      charges,
+			ExchangeRate: rate,
+			Cost:         convert(charges*subscription.Price, rate),
+			PeriodPrice:  convert(periodPrice, rate),
+		}
+
+		sum.Subscriptions = append(sum.Subscriptions, cost)
+		sum.Total += cost.Cost
+		sum.PeriodTotal += cost.PeriodPrice
+	}
+
+	logging.FromContext(context).Debug("price sum",
+		zap.Stringer("user_id", userID), zap.Int("subscriptions", len(sum.Subscriptions)), zap.String("currency", string(currency)))
+
+	return sum, nil
+}
+
+func (s *SubscriptionsService) Create(context context.Context, subscription domain.Subscription) (domain.Subscription, error) {
+	subscription, err := s.prepareSubscription(context, subscription)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
 
 	return s.subscriptions.Create(context, subscription)
 }
 
-func (s *SubscriptionsService) UpdateByID(context context.Context, id uuid.UUID, parameters repository.UpdateParameters) error {
-	return s.subscriptions.UpdateByID(context, id, parameters)
+// UpdateByID applies parameters to the subscription if its current version
+// is one of versions, or any version if there are none.
+func (s *SubscriptionsService) UpdateByID(context context.Context, id uuid.UUID, versions []int64, parameters repository.UpdateParameters) (domain.Subscription, error) {
+	version, err := s.prepareUpdate(context, id, versions, parameters)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+
+	return s.subscriptions.UpdateByID(context, id, []int64{version}, parameters)
+}
+
+// DeleteByID deletes the subscription if its current version is one of
+// versions, or any version if there are none.
+func (s *SubscriptionsService) DeleteByID(context context.Context, id uuid.UUID, versions []int64) error {
+	if _, err := s.getAuthorized(context, id, false); err != nil {
+		return err
+	}
+
+	return s.subscriptions.DeleteByID(context, id, versions)
 }
 
-func (s *SubscriptionsService) DeleteByID(context context.Context, id uuid.UUID) error {
-	return s.subscriptions.DeleteByID(context, id)
+func (s *SubscriptionsService) RestoreByID(context context.Context, id uuid.UUID) (domain.Subscription, error) {
+	if _, err := s.getAuthorized(context, id, true); err != nil {
+		return domain.Subscription{}, err
+	}
+
+	return s.subscriptions.RestoreByID(context, id)
+}
+
+func (s *SubscriptionsService) GetHistoryByID(context context.Context, id uuid.UUID) ([]domain.AuditRecord, error) {
+	if _, err := s.getAuthorized(context, id, true); err != nil {
+		return []domain.AuditRecord{}, err
+	}
+
+	return s.audit.GetListBySubscriptionID(context, id)
+}
+
+// Batch applies operations in order. In atomic mode the operations are
+// validated up front and written in one transaction, so a single failure
+// leaves everything unchanged and the other operations are reported as
+// aborted. Otherwise every operation succeeds or fails on its own.
+func (s *SubscriptionsService) Batch(context context.Context, operations []repository.BatchOperation, atomic bool) ([]repository.BatchResult, error) {
+	if len(operations) == 0 || len(operations) > maxBatchSize {
+		return []repository.BatchResult{}, domain.ErrInvalidBatch
+	}
+
+	for _, operation := range operations {
+		if !operation.Type.Valid() {
+			return []repository.BatchResult{}, domain.ErrInvalidBatch
+		}
+	}
+
+	if !atomic {
+		return s.batchEach(context, operations), nil
+	}
+
+	// Versions are checked against the subscriptions before the batch, so
+	// a second change of a subscription would always mismatch.
+	changed := make(map[uuid.UUID]bool, len(operations))
+
+	for i, operation := range operations {
+		if operation.Type == repository.BatchOperationCreate {
+			continue
+		}
+
+		if changed[operation.ID] {
+			return []repository.BatchResult{}, &domain.FieldError{Field: fmt.Sprintf("operations[%d].id", i), Err: domain.ErrDuplicateOperation}
+		}
+
+		changed[operation.ID] = true
+	}
+
+	prepared := make([]repository.BatchOperation, len(operations))
+
+	for i, operation := range operations {
+		var err error
+
+		switch operation.Type {
+		case repository.BatchOperationCreate:
+			operation.Subscription, err = s.prepareSubscription(context, operation.Subscription)
+		case repository.BatchOperationUpdate:
+			operation.Version, err = s.prepareUpdate(context, operation.ID, operation.Versions(), operation.Parameters)
+		case repository.BatchOperationDelete:
+			_, err = s.getAuthorized(context, operation.ID, false)
+		}
+
+		if err != nil {
+			logging.FromContext(context).Info("batch aborted", zap.Int("index", i), zap.Error(err))
+
+			return abortedBatch(len(operations), i, err), nil
+		}
+
+		prepared[i] = operation
+	}
+
+	subscriptions, err := s.subscriptions.Batch(context, prepared)
+	if err != nil {
+		var batchErr *repository.BatchError
+		if errors.As(err, &batchErr) {
+			logging.FromContext(context).Info("batch aborted", zap.Int("index", batchErr.Index), zap.Error(batchErr.Err))
+
+			return abortedBatch(len(operations), batchErr.Index, batchErr.Err), nil
+		}
+
+		return []repository.BatchResult{}, err
+	}
+
+	results := make([]repository.BatchResult, len(operations))
+
+	for i, operation := range prepared {
+		if operation.Type != repository.BatchOperationDelete {
+			results[i].Subscription = &subscriptions[i]
+		}
+	}
+
+	return results, nil
+}
+
+// CreateBatch validates subscriptions and creates the valid ones in one
+// transaction. Invalid subscriptions are reported in their results and do
+// not prevent the others from being created. A dry run only validates.
+func (s *SubscriptionsService) CreateBatch(context context.Context, subscriptions []domain.Subscription, dryRun bool) ([]repository.BatchResult, error) {
+	if len(subscriptions) > maxBatchSize {
+		return []repository.BatchResult{}, domain.ErrInvalidBatch
+	}
+
+	results := make([]repository.BatchResult, len(subscriptions))
+	operations := make([]repository.BatchOperation, 0, len(subscriptions))
+	indexes := make([]int, 0, len(subscriptions))
+
+	for i, subscription := range subscriptions {
+		subscription, err := s.prepareSubscription(context, subscription)
+		if err != nil {
+			results[i].Err = err
+
+			continue
+		}
+
+		results[i].Subscription = &subscription
+
+		operations = append(operations, repository.BatchOperation{
+			Type:         repository.BatchOperationCreate,
+			Subscription: subscription,
+		})
+		indexes = append(indexes, i)
+	}
+
+	if dryRun || len(operations) == 0 {
+		return results, nil
+	}
+
+	created, err := s.subscriptions.Batch(context, operations)
+	if err != nil {
+		var batchErr *repository.BatchError
+		if !errors.As(err, &batchErr) {
+			return []repository.BatchResult{}, err
+		}
+
+		logging.FromContext(context).Info("batch aborted", zap.Int("index", indexes[batchErr.Index]), zap.Error(batchErr.Err))
+
+		for j, i := range indexes {
+			results[i].Subscription = nil
+			results[i].Err = domain.ErrBatchAborted
+
+			if j == batchErr.Index {
+				results[i].Err = batchErr.Err
+			}
+		}
+
+		return results, nil
+	}
+
+	for j, i := range indexes {
+		results[i].Subscription = &created[j]
+	}
+
+	return results, nil
+}
+
+func (s *SubscriptionsService) batchEach(context context.Context, operations []repository.BatchOperation) []repository.BatchResult {
+	results := make([]repository.BatchResult, len(operations))
+
+	for i, operation := range operations {
+		var (
+			subscription domain.Subscription
+			err          error
+		)
+
+		switch operation.Type {
+		case repository.BatchOperationCreate:
+			subscription, err = s.Create(context, operation.Subscription)
+		case repository.BatchOperationUpdate:
+			subscription, err = s.UpdateByID(context, operation.ID, operation.Versions(), operation.Parameters)
+		case repository.BatchOperationDelete:
+			err = s.DeleteByID(context, operation.ID, operation.Versions())
+		}
+
+		if err != nil {
+			results[i].Err = err
+		} else if operation.Type != repository.BatchOperationDelete {
+			results[i].Subscription = &subscription
+		}
+	}
+
+	return results
+}
+
+func (s *SubscriptionsService) PurgeDeleted(context context.Context, retention time.Duration) (int64, error) {
+	if err := authorizeAdmin(context); err != nil {
+		return 0, err
+	}
+
+	return s.subscriptions.PurgeDeleted(context, time.Now().Add(-retention))
+}
+
+// prepareUpdate checks parameters against the current state of the
+// subscription and returns the version the update must be conditional on.
+// No versions skip the check against the caller's versions, but the write
+// is still conditional on the version the merged state was validated
+// against.
+func (s *SubscriptionsService) prepareUpdate(context context.Context, id uuid.UUID, versions []int64, parameters repository.UpdateParameters) (int64, error) {
+	if parameters == (repository.UpdateParameters{}) {
+		return 0, domain.ErrNoUpdateParameters
+	}
+
+	subscription, err := s.getAuthorized(context, id, false)
+	if err != nil {
+		return 0, err
+	}
+
+	if parameters.UserID != nil {
+		if err := s.authorize(context, *parameters.UserID); err != nil {
+			return 0, err
+		}
+	}
+
+	if len(versions) != 0 && !slices.Contains(versions, subscription.Version) {
+		return 0, domain.ErrVersionMismatch
+	}
+
+	if err := validation.Subscription(mergeSubscription(subscription, parameters)); err != nil {
+		return 0, err
+	}
+
+	return subscription.Version, nil
+}
+
+func abortedBatch(size, failed int, err error) []repository.BatchResult {
+	results := make([]repository.BatchResult, size)
+
+	for i := range results {
+		results[i].Err = domain.ErrBatchAborted
+	}
+
+	results[failed].Err = err
+
+	return results
+}
+
+func convert(amount int64, rate float64) int64 {
+	return int64(math.Round(float64(amount) * rate))
+}
+
+// prepareSubscription checks that the caller may create a subscription for
+// its user, fills in the defaults, validates it and assigns it an ID.
+func (s *SubscriptionsService) prepareSubscription(context context.Context, subscription domain.Subscription) (domain.Subscription, error) {
+	if err := s.authorize(context, subscription.UserID); err != nil {
+		return domain.Subscription{}, err
+	}
+
+	if subscription.Currency == "" {
+		subscription.Currency = domain.DefaultCurrency
+	}
+
+	if subscription.BillingPeriod == "" {
+		subscription.BillingPeriod = domain.BillingPeriodMonthly
+	}
+
+	if err := validation.Subscription(subscription); err != nil {
+		return domain.Subscription{}, err
+	}
+
+	subscription.ID = uuid.New()
+
+	return subscription, nil
+}
+
+func mergeSubscription(subscription domain.Subscription, parameters repository.UpdateParameters) domain.Subscription {
+	if parameters.ServiceName != nil {
+		subscription.ServiceName = *parameters.ServiceName
+	}
+	if parameters.Price != nil {
+		subscription.Price = *parameters.Price
+	}
+	if parameters.Currency != nil {
+		subscription.Currency = *parameters.Currency
+	}
+	if parameters.BillingPeriod != nil {
+		subscription.BillingPeriod = *parameters.BillingPeriod
+	}
+	if parameters.UserID != nil {
+		subscription.UserID = *parameters.UserID
+	}
+	if parameters.StartDate != nil {
+		subscription.StartDate = *parameters.StartDate
+	}
+	if parameters.EndDate != nil {
+		subscription.EndDate = parameters.EndDate
+	}
+	if parameters.ClearEndDate {
+		subscription.EndDate = nil
+	}
+
+	return subscription
 }
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mirrorblade/subscriptions/internal/domain"
+	"github.com/mirrorblade/subscriptions/internal/rbac"
+	"github.com/mirrorblade/subscriptions/internal/repository"
+)
+
+func TestBatchDuplicateIDs(t *testing.T) {
+	id := uuid.New()
+
+	operations := []repository.BatchOperation{
+		{Type: repository.BatchOperationCreate},
+		{Type: repository.BatchOperationUpdate, ID: id, Version: 3},
+		{Type: repository.BatchOperationCreate},
+		{Type: repository.BatchOperationDelete, ID: id, Version: 3},
+	}
+
+	s := NewSubscriptionsService(nil, nil, nil, nil)
+	context := domain.WithPrincipal(context.Background(), domain.Principal{UserID: uuid.New()})
+
+	_, err := s.Batch(context, operations, true)
+
+	var fieldErr *domain.FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "operations[3].id" || !errors.Is(err, domain.ErrDuplicateOperation) {
+		t.Errorf("err = %v, want operations[3].id: %v", err, domain.ErrDuplicateOperation)
+	}
+}
+
+// periodSubscriptions returns the same subscriptions for every period.
+type periodSubscriptions struct {
+	repository.Subscriptions
+	subscriptions []domain.Subscription
+}
+
+func (p *periodSubscriptions) GetListInPeriodByUserID(context.Context, uuid.UUID, repository.GetSumParameters) ([]domain.Subscription, error) {
+	return p.subscriptions, nil
+}
+
+type sameRates struct{}
+
+func (sameRates) GetRate(context.Context, domain.Currency, domain.Currency) (float64, error) {
+	return 1, nil
+}
+
+func TestPriceSumCurrency(t *testing.T) {
+	userID := uuid.New()
+	month := func(year int, month time.Month) time.Time {
+		return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	}
+	endDate := month(2025, 3)
+
+	subscription := func(currency domain.Currency, startDate time.Time, endDate *time.Time) domain.Subscription {
+		return domain.Subscription{
+			ServiceName:   "Yandex Plus",
+			Price:         400,
+			Currency:      currency,
+			BillingPeriod: domain.BillingPeriodMonthly,
+			UserID:        userID,
+			StartDate:     startDate,
+			EndDate:       endDate,
+		}
+	}
+
+	fromDate, toDate := month(2025, 7), month(2025, 12)
+
+	tests := []struct {
+		name          string
+		subscriptions []domain.Subscription
+		currency      domain.Currency
+		err           error
+	}{
+		{
+			name: "subscription in another currency ended before the period",
+			subscriptions: []domain.Subscription{
+				subscription("USD", month(2025, 1), &endDate),
+				subscription("EUR", month(2025, 7), nil),
+			},
+			currency: "EUR",
+		},
+		{
+			name: "subscription in another currency starts after the period",
+			subscriptions: []domain.Subscription{
+				subscription("EUR", month(2025, 7), nil),
+				subscription("USD", month(2026, 1), nil),
+			},
+			currency: "EUR",
+		},
+		{
+			name: "no subscription in the period",
+			subscriptions: []domain.Subscription{
+				subscription("USD", month(2025, 1), &endDate),
+			},
+			currency: domain.DefaultCurrency,
+		},
+		{
+			name: "subscriptions in different currencies in the period",
+			subscriptions: []domain.Subscription{
+				subscription("USD", month(2025, 1), nil),
+				subscription("EUR", month(2025, 7), nil),
+			},
+			err: domain.ErrCurrencyRequired,
+		},
+	}
+
+	context := domain.WithPrincipal(context.Background(), domain.Principal{UserID: userID})
+
+	for _, tt := range tests {
+		s := NewSubscriptionsService(&periodSubscriptions{subscriptions: tt.subscriptions}, sameRates{}, nil, &rbac.Policy{})
+
+		sum, err := s.GetPriceSumByUserID(context, userID, repository.GetSumParameters{FromDate: &fromDate, ToDate: &toDate})
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.err)
+
+			continue
+		}
+
+		if err == nil && sum.Currency != tt.currency {
+			t.Errorf("%s: currency = %s, want %s", tt.name, sum.Currency, tt.currency)
+		}
+	}
+}
+
+// versionedSubscriptions holds a single subscription and records the
+// versions its changes are conditional on.
+type versionedSubscriptions struct {
+	repository.Subscriptions
+	subscription domain.Subscription
+	versions     []int64
+}
+
+func (v *versionedSubscriptions) GetByID(context.Context, uuid.UUID, bool) (domain.Subscription, error) {
+	return v.subscription, nil
+}
+
+func (v *versionedSubscriptions) UpdateByID(_ context.Context, _ uuid.UUID, versions []int64, _ repository.UpdateParameters) (domain.Subscription, error) {
+	v.versions = versions
+
+	return v.subscription, nil
+}
+
+func (v *versionedSubscriptions) DeleteByID(_ context.Context, _ uuid.UUID, versions []int64) error {
+	v.versions = versions
+
+	return nil
+}
+
+func TestChangeVersions(t *testing.T) {
+	userID := uuid.New()
+	price := int64(500)
+
+	tests := []struct {
+		name     string
+		delete   bool
+		versions []int64
+		written  []int64
+		err      error
+	}{
+		{"update of any version", false, nil, []int64{4}, nil},
+		{"update of a listed version", false, []int64{3, 4}, []int64{4}, nil},
+		{"update of another version", false, []int64{3, 5}, nil, domain.ErrVersionMismatch},
+		{"delete of any version", true, nil, nil, nil},
+		{"delete of listed versions", true, []int64{3, 4}, []int64{3, 4}, nil},
+	}
+
+	context := domain.WithPrincipal(context.Background(), domain.Principal{UserID: userID})
+
+	for _, tt := range tests {
+		subscriptions := &versionedSubscriptions{subscription: domain.Subscription{
+			ServiceName:   "Yandex Plus",
+			Price:         400,
+			Currency:      domain.DefaultCurrency,
+			BillingPeriod: domain.BillingPeriodMonthly,
+			UserID:        userID,
+			StartDate:     time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+			Version:       4,
+		}}
+		s := NewSubscriptionsService(subscriptions, nil, nil, &rbac.Policy{})
+
+		var err error
+		if tt.delete {
+			err = s.DeleteByID(context, uuid.New(), tt.versions)
+		} else {
+			_, err = s.UpdateByID(context, uuid.New(), tt.versions, repository.UpdateParameters{Price: &price})
+		}
+
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.err)
+		}
+
+		if !slices.Equal(subscriptions.versions, tt.written) {
+			t.Errorf("%s: written versions = %v, want %v", tt.name, subscriptions.versions, tt.written)
+		}
+	}
+}
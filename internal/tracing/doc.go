@@ -0,0 +1,3 @@
+// Package tracing provides functionality for tracing requests through the
+// service and the database with OpenTelemetry
+package tracing
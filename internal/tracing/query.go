@@ -0,0 +1,106 @@
+package tracing
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	stringLiteral  = regexp.MustCompile(`'(?:[^']|'')*'`)
+	numericLiteral = regexp.MustCompile(`(^|[^\w$.])\d+(?:\.\d+)?`)
+	whitespace     = regexp.MustCompile(`\s+`)
+)
+
+// sanitize replaces the literals of query with placeholders, so that values
+// written into the query text do not end up in traces. Values passed as
+// arguments are never recorded.
+func sanitize(query string) string {
+	query = stringLiteral.ReplaceAllString(query, "?")
+	query = numericLiteral.ReplaceAllString(query, "${1}?")
+
+	return strings.TrimSpace(whitespace.ReplaceAllString(query, " "))
+}
+
+// operation is the first keyword of query, such as SELECT or WITH.
+func operation(query string) string {
+	keyword, _, _ := strings.Cut(strings.TrimSpace(query), " ")
+
+	return strings.ToUpper(keyword)
+}
+
+// QueryTracer records a span for every query and batch of a connection, with
+// the sanitized query text and the number of rows it returned or changed.
+type QueryTracer struct{}
+
+func NewQueryTracer() *QueryTracer {
+	return &QueryTracer{}
+}
+
+func (t *QueryTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	operation := operation(data.SQL)
+
+	ctx, _ = tracer().Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNamePostgreSQL,
+			semconv.DBNamespace(conn.Config().Database),
+			semconv.DBOperationName(operation),
+			semconv.DBQueryText(sanitize(data.SQL)),
+		),
+	)
+
+	return ctx
+}
+
+func (t *QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+
+	span.SetAttributes(semconv.DBResponseReturnedRows(int(data.CommandTag.RowsAffected())))
+	end(span, data.Err)
+}
+
+func (t *QueryTracer) TraceBatchStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	ctx, _ = tracer().Start(ctx, "BATCH",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNamePostgreSQL,
+			semconv.DBNamespace(conn.Config().Database),
+			semconv.DBOperationName("BATCH"),
+			semconv.DBOperationBatchSize(data.Batch.Len()),
+		),
+	)
+
+	return ctx
+}
+
+// TraceBatchQuery records the queries of a batch as events of its span,
+// since they are sent to the database together.
+func (t *QueryTracer) TraceBatchQuery(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchQueryData) {
+	span := trace.SpanFromContext(ctx)
+
+	span.AddEvent("query", trace.WithAttributes(
+		semconv.DBQueryText(sanitize(data.SQL)),
+		semconv.DBResponseReturnedRows(int(data.CommandTag.RowsAffected())),
+		attribute.Bool("error", data.Err != nil),
+	))
+}
+
+func (t *QueryTracer) TraceBatchEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchEndData) {
+	end(trace.SpanFromContext(ctx), data.Err)
+}
+
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
@@ -0,0 +1,161 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mirrorblade/subscriptions/internal/domain"
+	"github.com/mirrorblade/subscriptions/internal/repository"
+	"github.com/mirrorblade/subscriptions/internal/service"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	attributeSubscriptionID = attribute.Key("subscription.id")
+	attributeUserID         = attribute.Key("user.id")
+	attributeCount          = attribute.Key("subscriptions.count")
+	attributeTotal          = attribute.Key("subscriptions.total")
+	attributeBatchSize      = attribute.Key("batch.size")
+	attributeAtomic         = attribute.Key("batch.atomic")
+	attributeDryRun         = attribute.Key("dry_run")
+)
+
+// Subscriptions records a span for every call of a subscriptions service,
+// with the IDs it was called with and the number of subscriptions it
+// returned.
+type Subscriptions struct {
+	subscriptions service.Subscriptions
+}
+
+func NewSubscriptions(subscriptions service.Subscriptions) *Subscriptions {
+	return &Subscriptions{
+		subscriptions: subscriptions,
+	}
+}
+
+func (s *Subscriptions) GetByID(context context.Context, id uuid.UUID, includeDeleted bool) (subscription domain.Subscription, err error) {
+	context, span := start(context, "GetByID", attributeSubscriptionID.String(id.String()))
+	defer func() { end(span, err) }()
+
+	return s.subscriptions.GetByID(context, id, includeDeleted)
+}
+
+func (s *Subscriptions) GetList(context context.Context, parameters repository.ListParameters) (list domain.SubscriptionList, err error) {
+	context, span := start(context, "GetList")
+	defer func() { end(span, err) }()
+
+	list, err = s.subscriptions.GetList(context, parameters)
+
+	span.SetAttributes(attributeCount.Int(len(list.Subscriptions)))
+
+	return list, err
+}
+
+func (s *Subscriptions) Export(context context.Context, parameters repository.ListParameters, fn func(domain.Subscription) error) (err error) {
+	context, span := start(context, "Export")
+	defer func() { end(span, err) }()
+
+	count := 0
+
+	err = s.subscriptions.Export(context, parameters, func(subscription domain.Subscription) error {
+		count++
+
+		return fn(subscription)
+	})
+
+	span.SetAttributes(attributeCount.Int(count))
+
+	return err
+}
+
+func (s *Subscriptions) Search(context context.Context, parameters repository.SearchParameters) (result domain.SubscriptionSearchResult, err error) {
+	context, span := start(context, "Search")
+	defer func() { end(span, err) }()
+
+	result, err = s.subscriptions.Search(context, parameters)
+
+	span.SetAttributes(attributeCount.Int(len(result.Subscriptions)), attributeTotal.Int64(result.Total))
+
+	return result, err
+}
+
+func (s *Subscriptions) GetPriceSumByUserID(context context.Context, userID uuid.UUID, parameters repository.GetSumParameters) (sum domain.PriceSum, err error) {
+	context, span := start(context, "GetPriceSumByUserID", attributeUserID.String(userID.String()))
+	defer func() { end(span, err) }()
+
+	sum, err = s.subscriptions.GetPriceSumByUserID(context, userID, parameters)
+
+	span.SetAttributes(attributeCount.Int(len(sum.Subscriptions)))
+
+	return sum, err
+}
+
+func (s *Subscriptions) Create(context context.Context, subscription domain.Subscription) (created domain.Subscription, err error) {
+	context, span := start(context, "Create", attributeUserID.String(subscription.UserID.String()))
+	defer func() { end(span, err) }()
+
+	created, err = s.subscriptions.Create(context, subscription)
+
+	span.SetAttributes(attributeSubscriptionID.String(created.ID.String()))
+
+	return created, err
+}
+
+func (s *Subscriptions) UpdateByID(context context.Context, id uuid.UUID, versions []int64, parameters repository.UpdateParameters) (subscription domain.Subscription, err error) {
+	context, span := start(context, "UpdateByID", attributeSubscriptionID.String(id.String()))
+	defer func() { end(span, err) }()
+
+	return s.subscriptions.UpdateByID(context, id, versions, parameters)
+}
+
+func (s *Subscriptions) DeleteByID(context context.Context, id uuid.UUID, versions []int64) (err error) {
+	context, span := start(context, "DeleteByID", attributeSubscriptionID.String(id.String()))
+	defer func() { end(span, err) }()
+
+	return s.subscriptions.DeleteByID(context, id, versions)
+}
+
+func (s *Subscriptions) RestoreByID(context context.Context, id uuid.UUID) (subscription domain.Subscription, err error) {
+	context, span := start(context, "RestoreByID", attributeSubscriptionID.String(id.String()))
+	defer func() { end(span, err) }()
+
+	return s.subscriptions.RestoreByID(context, id)
+}
+
+func (s *Subscriptions) GetHistoryByID(context context.Context, id uuid.UUID) (records []domain.AuditRecord, err error) {
+	context, span := start(context, "GetHistoryByID", attributeSubscriptionID.String(id.String()))
+	defer func() { end(span, err) }()
+
+	return s.subscriptions.GetHistoryByID(context, id)
+}
+
+func (s *Subscriptions) PurgeDeleted(context context.Context, retention time.Duration) (count int64, err error) {
+	context, span := start(context, "PurgeDeleted")
+	defer func() { end(span, err) }()
+
+	count, err = s.subscriptions.PurgeDeleted(context, retention)
+
+	span.SetAttributes(attributeCount.Int64(count))
+
+	return count, err
+}
+
+func (s *Subscriptions) Batch(context context.Context, operations []repository.BatchOperation, atomic bool) (results []repository.BatchResult, err error) {
+	context, span := start(context, "Batch", attributeBatchSize.Int(len(operations)), attributeAtomic.Bool(atomic))
+	defer func() { end(span, err) }()
+
+	return s.subscriptions.Batch(context, operations, atomic)
+}
+
+func (s *Subscriptions) CreateBatch(context context.Context, subscriptions []domain.Subscription, dryRun bool) (results []repository.BatchResult, err error) {
+	context, span := start(context, "CreateBatch", attributeBatchSize.Int(len(subscriptions)), attributeDryRun.Bool(dryRun))
+	defer func() { end(span, err) }()
+
+	return s.subscriptions.CreateBatch(context, subscriptions, dryRun)
+}
+
+func start(ctx context.Context, method string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer().Start(ctx, "service.Subscriptions."+method, trace.WithAttributes(attributes...))
+}
@@ -0,0 +1,89 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/mirrorblade/subscriptions/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServiceName names the service in traces.
+const ServiceName = "subscriptions"
+
+const instrumentation = "github.com/mirrorblade/subscriptions/internal/tracing"
+
+type Exporter string
+
+const (
+	ExporterNone   Exporter = "none"
+	ExporterOTLP   Exporter = "otlp"
+	ExporterStdout Exporter = "stdout"
+)
+
+// New installs the global tracer provider of config and the W3C trace context
+// propagator. It returns a function that flushes the spans that were not
+// exported yet and stops the provider. Without an exporter nothing is
+// recorded, but trace context is still propagated.
+func New(ctx context.Context, config config.Tracing) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	sampler, err := ratioSampler(config.Ratio)
+	if err != nil {
+		return nil, err
+	}
+
+	var exporter sdktrace.SpanExporter
+
+	switch Exporter(config.Exporter) {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		var options []otlptracehttp.Option
+		if config.Endpoint != "" {
+			options = append(options, otlptracehttp.WithEndpointURL(config.Endpoint))
+		}
+
+		exporter, err = otlptracehttp.New(ctx, options...)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", config.Exporter)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sampler),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(ServiceName))),
+	)
+
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// ratioSampler samples ratio of new traces. A ratio of zero samples no new
+// traces, but traces sampled by the caller are still continued.
+func ratioSampler(ratio float64) (sdktrace.Sampler, error) {
+	if ratio < 0 || ratio > 1 {
+		return nil, fmt.Errorf("trace ratio %v is not between 0 and 1", ratio)
+	}
+
+	return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio)), nil
+}
+
+func tracer() trace.Tracer {
+	return otel.Tracer(instrumentation)
+}
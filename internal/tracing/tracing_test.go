@@ -0,0 +1,49 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mirrorblade/subscriptions/internal/config"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestNewRatio(t *testing.T) {
+	for _, ratio := range []float64{-0.5, 1.5} {
+		if _, err := New(context.Background(), config.Tracing{Ratio: ratio}); err == nil {
+			t.Errorf("ratio %v: err = nil, want an error", ratio)
+		}
+	}
+}
+
+func TestSamplerRatio(t *testing.T) {
+	tests := []struct {
+		ratio float64
+		spans int
+	}{
+		{ratio: 0, spans: 0},
+		{ratio: 1, spans: 1},
+	}
+
+	for _, tt := range tests {
+		sampler, err := ratioSampler(tt.ratio)
+		if err != nil {
+			t.Fatalf("ratio %v: %v", tt.ratio, err)
+		}
+
+		recorder := tracetest.NewSpanRecorder()
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSampler(sampler), sdktrace.WithSpanProcessor(recorder))
+
+		_, span := provider.Tracer(instrumentation).Start(context.Background(), "test")
+		span.End()
+
+		if spans := len(recorder.Ended()); spans != tt.spans {
+			t.Errorf("ratio %v: recorded spans = %d, want %d", tt.ratio, spans, tt.spans)
+		}
+
+		if err := provider.Shutdown(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,2 @@
+// Package validation provides functionality for validating subscriptions
+package validation
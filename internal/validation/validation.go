@@ -0,0 +1,160 @@
+package validation
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/mirrorblade/subscriptions/internal/domain"
+)
+
+const (
+	// DateLayout is the layout of dates in requests and files.
+	DateLayout = "01-2006"
+
+	// MaxServiceNameLength is the size of the service_name column.
+	MaxServiceNameLength = 255
+)
+
+// Validator collects the violations of every field it checks, so that they
+// can be reported at once.
+type Validator struct {
+	violations []*domain.FieldError
+}
+
+func (v *Validator) Add(field string, err error) {
+	v.violations = append(v.violations, &domain.FieldError{Field: field, Err: err})
+}
+
+// Check adds a violation of field unless ok.
+func (v *Validator) Check(ok bool, field string, err error) {
+	if !ok {
+		v.Add(field, err)
+	}
+}
+
+func (v *Validator) Valid() bool {
+	return len(v.violations) == 0
+}
+
+// Err returns a domain.ValidationError with the collected violations, or
+// nil if there are none.
+func (v *Validator) Err() error {
+	if v.Valid() {
+		return nil
+	}
+
+	return &domain.ValidationError{Violations: v.violations}
+}
+
+func (v *Validator) ID(field, value string) uuid.UUID {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		v.Add(field, domain.ErrInvalidID)
+	}
+
+	return id
+}
+
+func (v *Validator) Date(field, value string) time.Time {
+	date, err := time.Parse(DateLayout, value)
+	if err != nil {
+		v.Add(field, domain.ErrInvalidDate)
+	}
+
+	return date
+}
+
+// OptionalDate returns nil for an empty value.
+func (v *Validator) OptionalDate(field, value string) *time.Time {
+	if value == "" {
+		return nil
+	}
+
+	date, err := time.Parse(DateLayout, value)
+	if err != nil {
+		v.Add(field, domain.ErrInvalidDate)
+
+		return nil
+	}
+
+	return &date
+}
+
+func (v *Validator) ServiceName(field, serviceName string) {
+	v.Check(strings.TrimSpace(serviceName) != "" && utf8.RuneCountInString(serviceName) <= MaxServiceNameLength, field, domain.ErrInvalidServiceName)
+}
+
+func (v *Validator) Price(field string, price int64) {
+	v.Check(price >= 0, field, domain.ErrInvalidPrice)
+}
+
+func (v *Validator) Currency(field string, currency domain.Currency) {
+	v.Check(currency.Valid(), field, domain.ErrInvalidCurrency)
+}
+
+func (v *Validator) BillingPeriod(field string, billingPeriod domain.BillingPeriod) {
+	v.Check(billingPeriod.Valid(), field, domain.ErrInvalidBillingPeriod)
+}
+
+// Subscription checks every field of a subscription with its defaults
+// filled in.
+func Subscription(subscription domain.Subscription) error {
+	v := new(Validator)
+
+	v.ServiceName("service_name", subscription.ServiceName)
+	v.Price("price", subscription.Price)
+	v.Currency("currency", subscription.Currency)
+	v.BillingPeriod("billing_period", subscription.BillingPeriod)
+	v.Check(subscription.EndDate == nil || !subscription.EndDate.Before(subscription.StartDate), "end_date", domain.ErrInvalidDate)
+
+	return v.Err()
+}
+
+// SubscriptionInput is a subscription as clients send it. Currency and
+// billing period may be empty to use their defaults.
+type SubscriptionInput struct {
+	ServiceName   string
+	Price         int64
+	Currency      string
+	BillingPeriod string
+	UserID        string
+	StartDate     string
+	EndDate       string
+}
+
+// ParseSubscription converts input into a subscription and checks it,
+// reporting the violations of all fields together. The service name is
+// expected to be sanitized already.
+func ParseSubscription(input SubscriptionInput) (domain.Subscription, error) {
+	v := new(Validator)
+
+	subscription := domain.Subscription{
+		ServiceName:   input.ServiceName,
+		Price:         input.Price,
+		Currency:      domain.Currency(strings.ToUpper(input.Currency)),
+		BillingPeriod: domain.BillingPeriod(input.BillingPeriod),
+	}
+
+	v.ServiceName("service_name", subscription.ServiceName)
+	v.Price("price", subscription.Price)
+
+	if subscription.Currency != "" {
+		v.Currency("currency", subscription.Currency)
+	}
+
+	if subscription.BillingPeriod != "" {
+		v.BillingPeriod("billing_period", subscription.BillingPeriod)
+	}
+
+	subscription.UserID = v.ID("user_id", input.UserID)
+	subscription.StartDate = v.Date("start_date", input.StartDate)
+	subscription.EndDate = v.OptionalDate("end_date", input.EndDate)
+
+	if v.Valid() {
+		v.Check(subscription.EndDate == nil || !subscription.EndDate.Before(subscription.StartDate), "end_date", domain.ErrInvalidDate)
+	}
+
+	return subscription, v.Err()
+}
@@ -0,0 +1,2 @@
+// Package worker provides background jobs of the service
+package worker
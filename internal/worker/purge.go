@@ -0,0 +1,76 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/mirrorblade/subscriptions/internal/config"
+	"github.com/mirrorblade/subscriptions/internal/domain"
+	"github.com/mirrorblade/subscriptions/internal/logging"
+	"github.com/mirrorblade/subscriptions/internal/service"
+	"go.uber.org/zap"
+)
+
+type Purge struct {
+	service *service.Service
+
+	logger *zap.Logger
+
+	config *config.Purge
+}
+
+func NewPurge(service *service.Service, logger *zap.Logger, config *config.Purge) *Purge {
+	return &Purge{
+		service: service,
+		logger:  logger,
+		config:  config,
+	}
+}
+
+// Run hard-deletes soft-deleted subscriptions older than the configured
+// retention and expired idempotency keys every interval until context is
+// done. A zero interval disables the job, a zero retention keeps deleted
+// subscriptions forever.
+func (p *Purge) Run(context context.Context) {
+	if p.config.Interval <= 0 {
+		p.logger.Info("purge is disabled")
+
+		return
+	}
+
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		p.purge(context)
+
+		select {
+		case <-context.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Purge) purge(context context.Context) {
+	if p.config.Retention > 0 {
+		context = domain.WithActor(context, domain.SystemActor)
+		context = domain.WithPrincipal(context, domain.SystemPrincipal)
+		context = domain.WithTenant(context, domain.AllTenants)
+		context = logging.WithLogger(context, p.logger)
+
+		count, err := p.service.Subscriptions.PurgeDeleted(context, p.config.Retention)
+		if err != nil {
+			p.logger.Error("purge of deleted subscriptions", zap.Error(err))
+		} else {
+			p.logger.Info("purge of deleted subscriptions", zap.Int64("count", count))
+		}
+	}
+
+	count, err := p.service.Idempotency.PurgeExpired(context)
+	if err != nil {
+		p.logger.Error("purge of expired idempotency keys", zap.Error(err))
+	} else {
+		p.logger.Info("purge of expired idempotency keys", zap.Int64("count", count))
+	}
+}
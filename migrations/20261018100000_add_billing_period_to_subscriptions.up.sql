@@ -0,0 +1,3 @@
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS billing_period VARCHAR(16) NOT NULL DEFAULT 'monthly'
+    CHECK (billing_period IN ('weekly', 'monthly', 'quarterly', 'yearly'));
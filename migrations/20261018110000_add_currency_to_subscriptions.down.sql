@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS exchange_rates;
+
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS currency,
+    ALTER COLUMN price TYPE INT;
@@ -0,0 +1,11 @@
+ALTER TABLE subscriptions
+    ALTER COLUMN price TYPE BIGINT,
+    ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'RUB';
+
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    base CHAR(3) NOT NULL,
+    quote CHAR(3) NOT NULL,
+    rate NUMERIC(20, 10) NOT NULL CHECK (rate > 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (base, quote)
+);
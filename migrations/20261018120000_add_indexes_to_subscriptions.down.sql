@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS subscriptions_end_date_idx;
+DROP INDEX IF EXISTS subscriptions_start_date_idx;
+DROP INDEX IF EXISTS subscriptions_price_idx;
+DROP INDEX IF EXISTS subscriptions_service_name_idx;
+DROP INDEX IF EXISTS subscriptions_user_id_start_date_idx;
@@ -0,0 +1,5 @@
+CREATE INDEX IF NOT EXISTS subscriptions_user_id_start_date_idx ON subscriptions (user_id, start_date, id);
+CREATE INDEX IF NOT EXISTS subscriptions_service_name_idx ON subscriptions (service_name text_pattern_ops);
+CREATE INDEX IF NOT EXISTS subscriptions_price_idx ON subscriptions (price, id);
+CREATE INDEX IF NOT EXISTS subscriptions_start_date_idx ON subscriptions (start_date, id);
+CREATE INDEX IF NOT EXISTS subscriptions_end_date_idx ON subscriptions (end_date);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS subscriptions_audit (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id UUID NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    request_id VARCHAR(255) NOT NULL DEFAULT '',
+    before JSONB,
+    after JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS subscriptions_audit_subscription_id_idx ON subscriptions_audit (subscription_id, id);
+
+CREATE OR REPLACE RULE subscriptions_audit_no_update AS ON UPDATE TO subscriptions_audit DO INSTEAD NOTHING;
+CREATE OR REPLACE RULE subscriptions_audit_no_delete AS ON DELETE TO subscriptions_audit DO INSTEAD NOTHING;
@@ -0,0 +1,15 @@
+DROP POLICY IF EXISTS subscriptions_audit_tenant_isolation ON subscriptions_audit;
+ALTER TABLE subscriptions_audit NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE subscriptions_audit DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS subscriptions_tenant_isolation ON subscriptions;
+ALTER TABLE subscriptions NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE subscriptions DISABLE ROW LEVEL SECURITY;
+
+DROP INDEX IF EXISTS subscriptions_tenant_id_user_id_start_date_idx;
+CREATE INDEX IF NOT EXISTS subscriptions_user_id_start_date_idx ON subscriptions (user_id, start_date, id);
+
+ALTER TABLE subscriptions_audit DROP COLUMN IF EXISTS tenant_id;
+
+ALTER TABLE subscriptions DROP CONSTRAINT IF EXISTS subscriptions_tenant_id_check;
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS tenant_id;
@@ -0,0 +1,19 @@
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT 'default';
+ALTER TABLE subscriptions ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE subscriptions ADD CONSTRAINT subscriptions_tenant_id_check CHECK (tenant_id ~ '^[a-z0-9][a-z0-9_-]*$');
+
+ALTER TABLE subscriptions_audit ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT 'default';
+ALTER TABLE subscriptions_audit ALTER COLUMN tenant_id DROP DEFAULT;
+
+DROP INDEX IF EXISTS subscriptions_user_id_start_date_idx;
+CREATE INDEX IF NOT EXISTS subscriptions_tenant_id_user_id_start_date_idx ON subscriptions (tenant_id, user_id, start_date, id);
+
+ALTER TABLE subscriptions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE subscriptions FORCE ROW LEVEL SECURITY;
+CREATE POLICY subscriptions_tenant_isolation ON subscriptions
+    USING (tenant_id = current_setting('app.tenant_id', true) OR current_setting('app.all_tenants', true) = 'on');
+
+ALTER TABLE subscriptions_audit ENABLE ROW LEVEL SECURITY;
+ALTER TABLE subscriptions_audit FORCE ROW LEVEL SECURITY;
+CREATE POLICY subscriptions_audit_tenant_isolation ON subscriptions_audit
+    USING (tenant_id = current_setting('app.tenant_id', true) OR current_setting('app.all_tenants', true) = 'on');
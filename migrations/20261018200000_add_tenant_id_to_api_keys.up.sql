@@ -0,0 +1,3 @@
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT 'default';
+ALTER TABLE api_keys ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE api_keys ADD CONSTRAINT api_keys_tenant_id_check CHECK (tenant_id ~ '^[a-z0-9][a-z0-9_-]*$');
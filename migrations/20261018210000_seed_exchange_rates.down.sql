@@ -0,0 +1,2 @@
+-- Seeded rates that were not updated since.
+DELETE FROM exchange_rates WHERE quote = 'RUB' AND updated_at = '2026-10-01 00:00:00+00';
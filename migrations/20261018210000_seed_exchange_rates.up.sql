@@ -0,0 +1,20 @@
+-- Indicative rates to RUB, so that sums across currencies work on a fresh
+-- install. Rates of other pairs are derived from them. Replace them with
+-- current rates; rows that already exist are kept.
+INSERT INTO exchange_rates (base, quote, rate, updated_at) VALUES
+    ('USD', 'RUB', 81.0000000000, '2026-10-01 00:00:00+00'),
+    ('EUR', 'RUB', 94.0000000000, '2026-10-01 00:00:00+00'),
+    ('GBP', 'RUB', 108.0000000000, '2026-10-01 00:00:00+00'),
+    ('CHF', 'RUB', 101.0000000000, '2026-10-01 00:00:00+00'),
+    ('CNY', 'RUB', 11.3000000000, '2026-10-01 00:00:00+00'),
+    ('JPY', 'RUB', 0.5400000000, '2026-10-01 00:00:00+00'),
+    ('INR', 'RUB', 0.9200000000, '2026-10-01 00:00:00+00'),
+    ('AED', 'RUB', 22.0500000000, '2026-10-01 00:00:00+00'),
+    ('TRY', 'RUB', 1.9500000000, '2026-10-01 00:00:00+00'),
+    ('KZT', 'RUB', 0.1500000000, '2026-10-01 00:00:00+00'),
+    ('BYN', 'RUB', 27.0000000000, '2026-10-01 00:00:00+00'),
+    ('UZS', 'RUB', 0.0066000000, '2026-10-01 00:00:00+00'),
+    ('AMD', 'RUB', 0.2100000000, '2026-10-01 00:00:00+00'),
+    ('GEL', 'RUB', 30.0000000000, '2026-10-01 00:00:00+00'),
+    ('KGS', 'RUB', 0.9300000000, '2026-10-01 00:00:00+00')
+ON CONFLICT (base, quote) DO NOTHING;